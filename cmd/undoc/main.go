@@ -0,0 +1,118 @@
+// Command undoc prints constraints placed by `und` struct tags on a struct type as a Markdown table or JSON.
+//
+// Usage:
+//
+//	undoc [-format markdown|json] [-scope scope] [-tag json] import/path.TypeName
+//
+// undoc must be run in a module which can import the package and github.com/ngicks/und.
+// It generates a temporary program in the current directory which calls [validate.Document] for the type,
+// runs it by go run, and removes it.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var (
+	format = flag.String("format", "markdown", "output format, markdown or json")
+	scope  = flag.String("scope", "", "scope of `und` struct tags, e.g. create")
+	tag    = flag.String("tag", "json", "struct tag key to read field names from")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] import/path.TypeName\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "undoc: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(target string) error {
+	i := strings.LastIndex(target, ".")
+	if i <= 0 || !token.IsIdentifier(target[i+1:]) || strings.HasSuffix(target[:i], "/") {
+		return fmt.Errorf("target must be formatted as import/path.TypeName but is %q", target)
+	}
+	if *format != "markdown" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	dir, err := os.MkdirTemp(".", ".undoc")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := os.Create(filepath.Join(dir, "main.go"))
+	if err != nil {
+		return err
+	}
+	err = program.Execute(f, map[string]string{
+		"ImportPath": strconv.Quote(target[:i]),
+		"TypeName":   target[i+1:],
+		"Format":     strconv.Quote(*format),
+		"Scope":      strconv.Quote(*scope),
+		"Tag":        strconv.Quote(*tag),
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+var program = template.Must(template.New("").Parse(`// Code generated by undoc. DO NOT EDIT.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/ngicks/und/validate"
+
+	target {{.ImportPath}}
+)
+
+func main() {
+	docs, err := validate.DocumentScope(reflect.TypeFor[target.{{.TypeName}}](), {{.Scope}}, validate.FieldNameTag({{.Tag}}))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "undoc: %v\n", err)
+		os.Exit(1)
+	}
+	if {{.Format}} == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(docs)
+	} else {
+		err = validate.WriteMarkdown(os.Stdout, docs)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "undoc: %v\n", err)
+		os.Exit(1)
+	}
+}
+`))
@@ -0,0 +1,104 @@
+package elastic
+
+import (
+	"iter"
+
+	"github.com/ngicks/und/option"
+)
+
+// portable functions that can be copied from github.com/ngicks/und/elastic into github.com/ngicks/und/sliceund/elastic
+
+// Aggregation functions below only consider some values of e;
+// null elements are skipped.
+// They return a none option if e is undefined, null, empty or only has null elements.
+
+// somes returns an iterator over some values of e.
+func somes[T any](e Elastic[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if !e.IsDefined() {
+			return
+		}
+		for _, opt := range e.Unwrap().Value() {
+			if opt.IsSome() && !yield(opt.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// Fold applies f to init and each value of e, from first to last, then returns the accumulated result.
+// Fold returns a none option if e has no some value.
+func Fold[T, U any](e Elastic[T], init U, f func(acc U, t T) U) option.Option[U] {
+	acc := init
+	var seen bool
+	for t := range somes(e) {
+		acc = f(acc, t)
+		seen = true
+	}
+	if !seen {
+		return option.None[U]()
+	}
+	return option.Some(acc)
+}
+
+// Reduce is like [Fold] but uses the first some value of e as an initial value.
+func Reduce[T any](e Elastic[T], f func(acc, t T) T) option.Option[T] {
+	acc := option.None[T]()
+	for t := range somes(e) {
+		if acc.IsNone() {
+			acc = option.Some(t)
+			continue
+		}
+		acc = option.Some(f(acc.Value(), t))
+	}
+	return acc
+}
+
+// FirstSome returns the first some value of e.
+func FirstSome[T any](e Elastic[T]) option.Option[T] {
+	for t := range somes(e) {
+		return option.Some(t)
+	}
+	return option.None[T]()
+}
+
+// LastSome returns the last some value of e.
+func LastSome[T any](e Elastic[T]) option.Option[T] {
+	if !e.IsDefined() {
+		return option.None[T]()
+	}
+	opts := e.Unwrap().Value()
+	for i := len(opts) - 1; i >= 0; i-- {
+		if opts[i].IsSome() {
+			return opts[i]
+		}
+	}
+	return option.None[T]()
+}
+
+// MinFunc returns the minimal value of e using cmp to compare values.
+// If there are multiple minimal values, MinFunc returns the first one.
+func MinFunc[T any](e Elastic[T], cmp func(a, b T) int) option.Option[T] {
+	return Reduce(e, func(acc, t T) T {
+		if cmp(t, acc) < 0 {
+			return t
+		}
+		return acc
+	})
+}
+
+// MaxFunc returns the maximal value of e using cmp to compare values.
+// If there are multiple maximal values, MaxFunc returns the first one.
+func MaxFunc[T any](e Elastic[T], cmp func(a, b T) int) option.Option[T] {
+	return Reduce(e, func(acc, t T) T {
+		if cmp(t, acc) > 0 {
+			return t
+		}
+		return acc
+	})
+}
+
+// Sum returns the sum of values of e.
+func Sum[T Numeric](e Elastic[T]) option.Option[T] {
+	return Reduce(e, func(acc, t T) T { return acc + t })
+}
@@ -0,0 +1,94 @@
+// Package bulk writes request bodies for the Elasticsearch _bulk API.
+//
+// The body is NDJSON, newline delimited action/metadata lines each optionally followed by a source line.
+// Update actions are generated by [MarshalPartial] so that
+// only defined and null fields of und.Und and elastic.Elastic appear in the partial document,
+// while undefined fields are omitted.
+package bulk
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Meta is metadata of an action.
+type Meta struct {
+	Index           string `json:"_index,omitempty"`
+	ID              string `json:"_id,omitempty"`
+	Routing         string `json:"routing,omitempty"`
+	RequireAlias    bool   `json:"require_alias,omitempty"`
+	RetryOnConflict int    `json:"retry_on_conflict,omitempty"` // only for update.
+}
+
+// UpdateOption configures the source line of an update action.
+type UpdateOption struct {
+	// DocAsUpsert uses the partial document as upsert document if the target does not exist.
+	DocAsUpsert bool
+	// DetectNoop toggles detect_noop. The field is omitted if nil.
+	DetectNoop *bool
+}
+
+// Writer writes actions to an underlying io.Writer.
+//
+// Each action, including its source line, is written by a single Write call.
+// Writer is not goroutine safe.
+type Writer struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+// NewWriter returns a new Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Index writes an index action. source is encoded by encoding/json.
+func (w *Writer) Index(meta Meta, source any) error {
+	return w.write("index", meta, source)
+}
+
+// Create writes a create action. source is encoded by encoding/json.
+func (w *Writer) Create(meta Meta, source any) error {
+	return w.write("create", meta, source)
+}
+
+// Delete writes a delete action.
+func (w *Writer) Delete(meta Meta) error {
+	return w.write("delete", meta, nil)
+}
+
+// Update writes an update action whose partial document is doc encoded by [MarshalPartial].
+func (w *Writer) Update(meta Meta, doc any, opt UpdateOption) error {
+	partial, err := MarshalPartial(doc)
+	if err != nil {
+		return err
+	}
+	return w.write("update", meta, updateSource{
+		Doc:         partial,
+		DocAsUpsert: opt.DocAsUpsert,
+		DetectNoop:  opt.DetectNoop,
+	})
+}
+
+type updateSource struct {
+	Doc         json.RawMessage `json:"doc"`
+	DocAsUpsert bool            `json:"doc_as_upsert,omitempty"`
+	DetectNoop  *bool           `json:"detect_noop,omitempty"`
+}
+
+func (w *Writer) write(action string, meta Meta, source any) error {
+	w.buf.Reset()
+	// encoding/json's Encoder appends a newline for each value.
+	enc := json.NewEncoder(&w.buf)
+	if err := enc.Encode(map[string]Meta{action: meta}); err != nil {
+		return err
+	}
+	if source != nil {
+		if err := enc.Encode(source); err != nil {
+			return err
+		}
+	}
+	_, err := w.w.Write(w.buf.Bytes())
+	return err
+}
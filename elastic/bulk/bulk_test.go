@@ -0,0 +1,141 @@
+package bulk_test
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ngicks/und"
+	"github.com/ngicks/und/elastic"
+	"github.com/ngicks/und/elastic/bulk"
+	"github.com/ngicks/und/option"
+	"github.com/ngicks/und/sliceund"
+	"gotest.tools/v3/assert"
+)
+
+type embedded struct {
+	Inner und.Und[string] `json:"inner"`
+}
+
+type nested struct {
+	A und.Und[int] `json:"a"`
+	B und.Und[int] `json:"b"`
+}
+
+type doc struct {
+	embedded
+	Defined   und.Und[string]         `json:"defined"`
+	Null      und.Und[string]         `json:"null"`
+	Undefined und.Und[string]         `json:"undefined"`
+	Slice     sliceund.Und[string]    `json:"slice"`
+	Elastic   elastic.Elastic[string] `json:"elastic"`
+	ElaUnd    elastic.Elastic[string] `json:"ela_und"`
+	Opt       option.Option[int]      `json:"opt"`
+	OptNone   option.Option[int]      `json:"opt_none"`
+	Nested    und.Und[nested]         `json:"nested"`
+	Plain     string                  `json:"plain"`
+	Omit      string                  `json:"omit,omitempty"`
+	Skipped   und.Und[string]         `json:"-"`
+}
+
+func TestMarshalPartial(t *testing.T) {
+	d := doc{
+		embedded: embedded{Inner: und.Defined("inner")},
+		Defined:  und.Defined("foo"),
+		Null:     und.Null[string](),
+		Slice:    sliceund.Null[string](),
+		Elastic:  elastic.FromOptions(option.Some("a"), option.None[string]()),
+		Opt:      option.Some(5),
+		Nested:   und.Defined(nested{A: und.Null[int]()}),
+		Skipped:  und.Defined("skipped"),
+	}
+	bin, err := bulk.MarshalPartial(d)
+	assert.NilError(t, err)
+	assert.Equal(
+		t,
+		`{"defined":"foo","null":null,"slice":null,"elastic":["a",null],"opt":5,"nested":{"a":null},"plain":"","inner":"inner"}`,
+		string(bin),
+	)
+
+	_, err = bulk.MarshalPartial(1)
+	assert.ErrorIs(t, err, bulk.ErrNotStruct)
+}
+
+func TestMarshalPartial_field_options(t *testing.T) {
+	type sample struct {
+		UndPtr     *und.Und[string]         `json:"und_ptr"`
+		OptPtr     *option.Option[int]      `json:"opt_ptr"`
+		ElasticPtr *elastic.Elastic[string] `json:"elastic_ptr"`
+		NullPtr    *und.Und[string]         `json:"null_ptr"`
+		Time       time.Time                `json:"time,omitzero"`
+		SetTime    time.Time                `json:"set_time,omitzero"`
+		Zero       int                      `json:"zero,omitzero"`
+		Quoted     int                      `json:"quoted,string"`
+		QuotedPtr  *bool                    `json:"quoted_ptr,string"`
+		QuotedNil  *bool                    `json:"quoted_nil,string,omitempty"`
+	}
+	null := und.Null[string]()
+	tt := true
+	bin, err := bulk.MarshalPartial(sample{
+		NullPtr:   &null,
+		SetTime:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Quoted:    5,
+		QuotedPtr: &tt,
+	})
+	assert.NilError(t, err)
+	assert.Equal(
+		t,
+		`{"null_ptr":null,"set_time":"2024-01-02T03:04:05Z","quoted":"5","quoted_ptr":"true"}`,
+		string(bin),
+	)
+
+	e := elastic.FromValues("a", "b")
+	bin, err = bulk.MarshalPartial(sample{ElasticPtr: &e})
+	assert.NilError(t, err)
+	assert.Equal(t, `{"elastic_ptr":["a","b"],"quoted":"0","quoted_ptr":null}`, string(bin))
+}
+
+func TestWriter(t *testing.T) {
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			received = append(received, scanner.Text())
+		}
+		_, _ = w.Write([]byte(`{"errors":false}`))
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	w := bulk.NewWriter(&buf)
+	assert.NilError(t, w.Index(bulk.Meta{Index: "i", ID: "1"}, map[string]string{"foo": "bar"}))
+	assert.NilError(t, w.Create(bulk.Meta{Index: "i", ID: "2"}, map[string]string{"foo": "baz"}))
+	assert.NilError(t, w.Update(
+		bulk.Meta{Index: "i", ID: "3", RetryOnConflict: 3},
+		nested{A: und.Defined(1), B: und.Null[int]()},
+		bulk.UpdateOption{DocAsUpsert: true},
+	))
+	assert.NilError(t, w.Delete(bulk.Meta{Index: "i", ID: "4"}))
+
+	resp, err := http.Post(srv.URL+"/_bulk", "application/x-ndjson", &buf)
+	assert.NilError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.DeepEqual(t, []string{
+		`{"index":{"_index":"i","_id":"1"}}`,
+		`{"foo":"bar"}`,
+		`{"create":{"_index":"i","_id":"2"}}`,
+		`{"foo":"baz"}`,
+		`{"update":{"_index":"i","_id":"3","retry_on_conflict":3}}`,
+		`{"doc":{"a":1,"b":null},"doc_as_upsert":true}`,
+		`{"delete":{"_index":"i","_id":"4"}}`,
+	}, received)
+}
@@ -0,0 +1,266 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ngicks/und/undtag"
+)
+
+// ErrNotStruct is returned by [MarshalPartial] if the input is not a struct nor a pointer to a struct.
+var ErrNotStruct = errors.New("not struct")
+
+var (
+	undLikeTy     = reflect.TypeFor[undtag.UndLike]()
+	optionLikeTy  = reflect.TypeFor[undtag.OptionLike]()
+	elasticLikeTy = reflect.TypeFor[undtag.ElasticLike]()
+	marshalerTy   = reflect.TypeFor[json.Marshaler]()
+	isZeroerTy    = reflect.TypeFor[interface{ IsZero() bool }]()
+)
+
+// MarshalPartial encodes v as a partial document for the update API.
+//
+// v must be a struct or a pointer to a struct.
+// Fields are named after `json` struct tags, and embedded structs are flattened as encoding/json does.
+//
+//   - Undefined und.Und[T] and elastic.Elastic[T] fields (and their slice variants) are omitted.
+//   - None option.Option[T] fields are omitted.
+//   - nil pointers to those types, e.g. *und.Und[T], are treated as undefined and omitted.
+//   - Null fields are encoded as null, so that the update API clears them.
+//   - Defined fields are encoded as is. If the value is a struct, it is also encoded partially,
+//     since the update API merges objects recursively.
+//   - Other fields are encoded as is, honoring `json:",omitempty"`, `json:",omitzero"` and `json:",string"`
+//     as encoding/json does.
+func MarshalPartial(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("%w: nil pointer", ErrNotStruct)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: input is expected to be a struct type but is %s", ErrNotStruct, rv.Kind())
+	}
+	var buf bytes.Buffer
+	if err := encodeStruct(&buf, rv); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeStruct(buf *bytes.Buffer, rv reflect.Value) error {
+	buf.WriteByte('{')
+	wrote := false
+	err := walkFields(rv, map[string]bool{}, func(name string, fv reflect.Value, ft reflect.StructField) error {
+		enc, ok, err := encodeField(fv, ft)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", name, err)
+		}
+		if !ok {
+			return nil
+		}
+		if wrote {
+			buf.WriteByte(',')
+		}
+		wrote = true
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(enc)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// walkFields calls fn for each field as encoding/json sees them.
+// seen holds names already taken by outer structs.
+func walkFields(rv reflect.Value, seen map[string]bool, fn func(name string, fv reflect.Value, ft reflect.StructField) error) error {
+	rt := rv.Type()
+	var embedded []reflect.Value
+	for i := 0; i < rt.NumField(); i++ {
+		ft := rt.Field(i)
+		jsonTag := ft.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(jsonTag, ",")
+		if ft.Anonymous && name == "" {
+			fv := rv.Field(i)
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				embedded = append(embedded, fv)
+				continue
+			}
+		}
+		if !ft.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = ft.Name
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if err := fn(name, rv.Field(i), ft); err != nil {
+			return err
+		}
+	}
+	for _, fv := range embedded {
+		if err := walkFields(fv, seen, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasOption(opts string, opt string) bool {
+	for len(opts) > 0 {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeField encodes fv, the value of the field ft. ok is false if the field should be omitted.
+func encodeField(fv reflect.Value, ft reflect.StructField) (enc []byte, ok bool, err error) {
+	_, opts, _ := strings.Cut(ft.Tag.Get("json"), ",")
+	if hasOption(opts, "omitzero") && isZeroValue(fv) {
+		return nil, false, nil
+	}
+	if fv.Kind() == reflect.Pointer && isContainer(fv.Type().Elem()) {
+		if fv.IsNil() {
+			// treated as undefined.
+			return nil, false, nil
+		}
+		fv = fv.Elem()
+	}
+	rt := fv.Type()
+	switch {
+	case rt.Implements(undLikeTy):
+		u := fv.Interface().(undtag.UndLike)
+		switch {
+		case u.IsUndefined():
+			return nil, false, nil
+		case u.IsNull():
+			return []byte(`null`), true, nil
+		}
+		return encodeDefined(fv)
+	case rt.Implements(optionLikeTy):
+		if fv.Interface().(undtag.OptionLike).IsNone() {
+			return nil, false, nil
+		}
+		return encodeDefined(fv)
+	case rt.Kind() == reflect.Struct && !implementsMarshaler(rt):
+		var buf bytes.Buffer
+		if err := encodeStruct(&buf, fv); err != nil {
+			return nil, false, err
+		}
+		return buf.Bytes(), true, nil
+	}
+	if hasOption(opts, "omitempty") && isEmptyValue(fv) {
+		return nil, false, nil
+	}
+	if hasOption(opts, "string") {
+		enc, err = marshalQuoted(fv)
+	} else {
+		enc, err = json.Marshal(fv.Interface())
+	}
+	return enc, err == nil, err
+}
+
+func isContainer(rt reflect.Type) bool {
+	return rt.Implements(undLikeTy) || rt.Implements(optionLikeTy)
+}
+
+// marshalQuoted encodes fv as a field with `json:",string"`.
+// encoding/json decides which types the option applies to.
+func marshalQuoted(fv reflect.Value) ([]byte, error) {
+	rt := reflect.StructOf([]reflect.StructField{{Name: "V", Type: fv.Type(), Tag: `json:"v,string"`}})
+	wrapper := reflect.New(rt).Elem()
+	wrapper.Field(0).Set(fv)
+	enc, err := json.Marshal(wrapper.Interface())
+	if err != nil {
+		return nil, err
+	}
+	// strip {"v": and }.
+	return enc[len(`{"v":`) : len(enc)-1], nil
+}
+
+// encodeDefined encodes a defined container value.
+// If its value is a struct, it is encoded partially.
+func encodeDefined(fv reflect.Value) ([]byte, bool, error) {
+	// elastic types also have Value method; it returns the first element.
+	// They must be encoded as a whole.
+	if !fv.Type().Implements(elasticLikeTy) {
+		if vm := fv.MethodByName("Value"); vm.IsValid() && vm.Type().NumIn() == 0 && vm.Type().NumOut() == 1 {
+			inner := vm.Call(nil)[0]
+			if inner.Kind() == reflect.Struct && !implementsMarshaler(inner.Type()) {
+				var buf bytes.Buffer
+				if err := encodeStruct(&buf, inner); err != nil {
+					return nil, false, err
+				}
+				return buf.Bytes(), true, nil
+			}
+		}
+	}
+	enc, err := json.Marshal(fv.Interface())
+	return enc, err == nil, err
+}
+
+func implementsMarshaler(rt reflect.Type) bool {
+	return rt.Implements(marshalerTy) || reflect.PointerTo(rt).Implements(marshalerTy)
+}
+
+// isEmptyValue is same as the one in encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// isZeroValue reports whether v is zero as encoding/json does for `json:",omitzero"`:
+// IsZero method is used if v implements it, reflect.Value.IsZero otherwise.
+func isZeroValue(v reflect.Value) bool {
+	rt := v.Type()
+	switch {
+	case rt.Implements(isZeroerTy):
+		if (rt.Kind() == reflect.Pointer || rt.Kind() == reflect.Interface) && v.IsNil() {
+			return true
+		}
+		return v.Interface().(interface{ IsZero() bool }).IsZero()
+	case reflect.PointerTo(rt).Implements(isZeroerTy):
+		if !v.CanAddr() {
+			addressable := reflect.New(rt).Elem()
+			addressable.Set(v)
+			v = addressable
+		}
+		return v.Addr().Interface().(interface{ IsZero() bool }).IsZero()
+	}
+	return v.IsZero()
+}
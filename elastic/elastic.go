@@ -252,6 +252,13 @@ func (e *Elastic[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 		return err
 	}
 
+	e.append(t)
+	return nil
+}
+
+// append appends t to e.
+// If e is not defined or has no element, e will be replaced with a new Elastic[T].
+func (e *Elastic[T]) append(t option.Options[T]) {
 	if len(e.inner().Value()) == 0 {
 		*e = FromOptions(t...)
 	} else {
@@ -265,5 +272,4 @@ func (e *Elastic[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 			})
 		})
 	}
-	return nil
 }
@@ -0,0 +1,321 @@
+// Package fields decodes Elasticsearch documents with dotted keys,
+// e.g. the "fields" of search hits, into structs using data container types.
+//
+// Elasticsearch returns "fields" of search hits as flat dotted keys whose values are always arrays:
+//
+//	{"user.name": ["john"], "user.id": [1], "tags": ["foo", "bar"]}
+//
+// [Unmarshal] expands dotted keys into nested objects,
+// unwraps single element arrays for non-elastic fields (e.g. und.Und[T], option.Option[T] or plain T)
+// and feeds arrays directly to elastic fields (elastic.Elastic[T] or slices),
+// so that the "fields" can be decoded into the same struct as "_source".
+package fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ngicks/und/internal/structfield"
+	"github.com/ngicks/und/undtag"
+)
+
+var (
+	// ErrMultipleValues is returned by [Unmarshal] if an input has multiple values for a non-elastic field.
+	ErrMultipleValues = errors.New("multiple values for non-elastic field")
+	// ErrConflictingKeys is returned by [Unmarshal] if a dotted key conflicts with a non-object value,
+	// e.g. {"user": 1, "user.name": "john"}.
+	ErrConflictingKeys = errors.New("conflicting keys")
+)
+
+var (
+	elasticLikeTy = reflect.TypeFor[undtag.ElasticLike]()
+	undLikeTy     = reflect.TypeFor[undtag.UndLike]()
+	optionLikeTy  = reflect.TypeFor[undtag.OptionLike]()
+	unmarshalerTy = reflect.TypeFor[json.Unmarshaler]()
+	rawMessageTy  = reflect.TypeFor[json.RawMessage]()
+	byteSliceTy   = reflect.TypeFor[[]byte]()
+	jsonNull      = json.RawMessage(`null`)
+)
+
+// Unmarshal decodes data into v, which must be a non-nil pointer to a struct.
+//
+// Dotted keys in data are expanded into nested objects before decoding.
+// Then, guided by the type of v, values are reshaped as follows:
+//
+//   - for elastic fields (implementors of ElasticLike), arrays are fed as they are.
+//   - for slices and arrays, arrays are fed as they are.
+//   - for other fields, a single element array is unwrapped.
+//     An empty array is decoded as null.
+//     An array having multiple values results in an error wrapping [ErrMultipleValues].
+//
+// Keys without corresponding fields are ignored.
+func Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("fields: non-nil pointer is expected but is %T", v)
+	}
+	normalized, err := normalize(rv.Type().Elem(), &node{raw: data}, "")
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(normalized, v)
+}
+
+// node is a JSON value whose dotted keys are expanded.
+// Either or both of raw and children is set.
+type node struct {
+	raw      json.RawMessage
+	children map[string]*node
+	keys     []string // insertion order of children
+}
+
+func isObject(raw json.RawMessage) bool {
+	raw = bytes.TrimLeft(raw, " \t\r\n")
+	return len(raw) > 0 && raw[0] == '{'
+}
+
+func isArray(raw json.RawMessage) bool {
+	raw = bytes.TrimLeft(raw, " \t\r\n")
+	return len(raw) > 0 && raw[0] == '['
+}
+
+// expand parses raw of n as an object and populates n.children.
+func (n *node) expand(path string) error {
+	if n.raw == nil {
+		return nil
+	}
+	raw := n.raw
+	n.raw = nil
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return err
+	}
+	if n.children == nil {
+		n.children = make(map[string]*node, len(obj))
+	}
+	// Keys are sorted to make errors deterministic.
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if err := n.insert(strings.Split(k, "."), obj[k], path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *node) insert(parts []string, raw json.RawMessage, path string) error {
+	cur := n
+	for i, part := range parts {
+		path := joinPath(path, strings.Join(parts[:i+1], "."))
+		child, ok := cur.children[part]
+		if !ok {
+			child = &node{}
+			cur.children[part] = child
+			cur.keys = append(cur.keys, part)
+		}
+		if i == len(parts)-1 {
+			if child.children == nil && child.raw == nil {
+				child.raw = raw
+				return nil
+			}
+			// merging into an object expanded from other dotted keys.
+			if !isObject(raw) {
+				return fmt.Errorf("%w: %s", ErrConflictingKeys, path)
+			}
+			return child.mergeObject(raw, path)
+		}
+		if child.raw != nil {
+			if !isObject(child.raw) {
+				return fmt.Errorf("%w: %s", ErrConflictingKeys, path)
+			}
+			if err := child.expand(path); err != nil {
+				return err
+			}
+		}
+		if child.children == nil {
+			child.children = make(map[string]*node)
+		}
+		cur = child
+	}
+	return nil
+}
+
+func (n *node) mergeObject(raw json.RawMessage, path string) error {
+	if n.raw != nil {
+		if err := n.expand(path); err != nil {
+			return err
+		}
+	}
+	n.raw = raw
+	return n.expand(path)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// normalize reshapes n so that it can be decoded into rt by encoding/json.
+func normalize(rt reflect.Type, n *node, path string) (json.RawMessage, error) {
+	if n.children == nil && !isArray(n.raw) && !isObject(n.raw) {
+		return n.raw, nil
+	}
+
+	switch {
+	case rt.Implements(elasticLikeTy):
+		return normalizeArray(valueType(rt), n, path)
+	case rt.Implements(undLikeTy), rt.Implements(optionLikeTy):
+		return normalizeSingle(valueType(rt), n, path)
+	case rt.Kind() == reflect.Pointer:
+		return normalizeSingle(rt.Elem(), n, path)
+	case rt == byteSliceTy || rt == rawMessageTy:
+		return n.marshal()
+	case rt.Kind() == reflect.Slice, rt.Kind() == reflect.Array:
+		return normalizeArray(rt.Elem(), n, path)
+	case rt.Kind() == reflect.Struct && !implementsUnmarshaler(rt):
+		if isArray(n.raw) {
+			return normalizeSingle(rt, n, path)
+		}
+		return normalizeStruct(rt, n, path)
+	default:
+		if isArray(n.raw) {
+			return normalizeSingle(rt, n, path)
+		}
+		return n.marshal()
+	}
+}
+
+// normalizeArray feeds an array as is, normalizing its elements.
+// A non-array value is treated as a single element.
+func normalizeArray(elem reflect.Type, n *node, path string) (json.RawMessage, error) {
+	if !isArray(n.raw) {
+		return normalize(elem, n, path)
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(n.raw, &raws); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, raw := range raws {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		normalized, err := normalize(elem, &node{raw: raw}, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(normalized)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// normalizeSingle unwraps a single element array.
+func normalizeSingle(rt reflect.Type, n *node, path string) (json.RawMessage, error) {
+	if !isArray(n.raw) {
+		return normalize(rt, n, path)
+	}
+	// T is []U; the array is the value itself.
+	if base := derefType(rt); base != byteSliceTy && (base.Kind() == reflect.Slice || base.Kind() == reflect.Array) {
+		return normalize(rt, n, path)
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(n.raw, &raws); err != nil {
+		return nil, err
+	}
+	switch len(raws) {
+	case 0:
+		return jsonNull, nil
+	case 1:
+		return normalize(rt, &node{raw: raws[0]}, path)
+	}
+	return nil, fmt.Errorf("%w: %s has %d values", ErrMultipleValues, path, len(raws))
+}
+
+func normalizeStruct(rt reflect.Type, n *node, path string) (json.RawMessage, error) {
+	if err := n.expand(path); err != nil {
+		return nil, err
+	}
+	fields := structfield.Visible(rt, "json")
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	wrote := false
+	for _, k := range n.keys {
+		// encoding/json matches keys case-insensitively, preferring exact match.
+		f, ok := structfield.Match(fields, k)
+		if !ok {
+			continue
+		}
+		normalized, err := normalize(f.Type, n.children[k], joinPath(path, k))
+		if err != nil {
+			return nil, err
+		}
+		if wrote {
+			buf.WriteByte(',')
+		}
+		wrote = true
+		key, _ := json.Marshal(k)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(normalized)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshal encodes n back into JSON.
+func (n *node) marshal() (json.RawMessage, error) {
+	if n.children == nil {
+		return n.raw, nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range n.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		buf.Write(key)
+		buf.WriteByte(':')
+		v, err := n.children[k].marshal()
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// valueType returns the type of T for data container types via its Value method.
+func valueType(rt reflect.Type) reflect.Type {
+	m, ok := rt.MethodByName("Value")
+	if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 {
+		// unknown implementor; decode it as is.
+		return reflect.TypeFor[json.RawMessage]()
+	}
+	return m.Type.Out(0)
+}
+
+func derefType(rt reflect.Type) reflect.Type {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return rt
+}
+
+func implementsUnmarshaler(rt reflect.Type) bool {
+	return rt.Implements(unmarshalerTy) || reflect.PointerTo(rt).Implements(unmarshalerTy)
+}
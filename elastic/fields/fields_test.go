@@ -0,0 +1,142 @@
+package fields_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ngicks/und"
+	"github.com/ngicks/und/elastic"
+	"github.com/ngicks/und/elastic/fields"
+	"github.com/ngicks/und/option"
+	"gotest.tools/v3/assert"
+)
+
+type doc struct {
+	Embedded
+	User    und.Und[user]                 `json:"user"`
+	Tags    elastic.Elastic[string]       `json:"tags"`
+	Plain   []int                         `json:"plain"`
+	Opt     option.Option[int]            `json:"opt"`
+	Scalar  string                        `json:"scalar"`
+	At      und.Und[time.Time]            `json:"at"`
+	Null    und.Und[string]               `json:"null"`
+	Comment elastic.Elastic[comment]      `json:"comment"`
+	Matrix  und.Und[[]int]                `json:"matrix"`
+	Nested  elastic.Elastic[nestedObject] `json:"nested"`
+}
+
+type Embedded struct {
+	Inner string `json:"inner"`
+}
+
+type user struct {
+	Name und.Und[string] `json:"name"`
+	ID   int             `json:"id"`
+	Addr address         `json:"addr"`
+}
+
+type address struct {
+	City string `json:"city"`
+}
+
+type comment struct {
+	Body string `json:"body"`
+}
+
+type nestedObject struct {
+	First und.Und[string] `json:"first"`
+	Last  und.Und[string] `json:"last"`
+}
+
+func TestUnmarshal_fields(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := `{
+		"inner": ["inner"],
+		"user.name": ["john"],
+		"user.id": [15],
+		"user.addr.city": ["tokyo"],
+		"tags": ["foo", "bar"],
+		"plain": [1, 2, 3],
+		"opt": [5],
+		"scalar": ["scalar"],
+		"at": ["2024-01-02T03:04:05Z"],
+		"null": [],
+		"comment.body": ["hello"],
+		"matrix": [1],
+		"nested": [{"first": ["a"], "last": ["b"]}, {"first": ["c"]}],
+		"unknown.field": [1]
+	}`
+	var d doc
+	assert.NilError(t, fields.Unmarshal([]byte(input), &d))
+
+	assert.Equal(t, "inner", d.Inner)
+	assert.Equal(t, "john", d.User.Value().Name.Value())
+	assert.Equal(t, 15, d.User.Value().ID)
+	assert.Equal(t, "tokyo", d.User.Value().Addr.City)
+	assert.Assert(t, elastic.Equal(elastic.FromValues("foo", "bar"), d.Tags))
+	assert.DeepEqual(t, []int{1, 2, 3}, d.Plain)
+	assert.Assert(t, option.Equal(option.Some(5), d.Opt))
+	assert.Equal(t, "scalar", d.Scalar)
+	assert.Assert(t, d.At.Value().Equal(at))
+	assert.Assert(t, d.Null.IsNull())
+	assert.Assert(t, elastic.Equal(elastic.FromValue(comment{"hello"}), d.Comment))
+	assert.DeepEqual(t, []int{1}, d.Matrix.Value())
+	assert.Assert(t, elastic.Equal(
+		elastic.FromValues(
+			nestedObject{und.Defined("a"), und.Defined("b")},
+			nestedObject{First: und.Defined("c")},
+		),
+		d.Nested,
+	))
+}
+
+func TestUnmarshal_source(t *testing.T) {
+	// _source may be indexed with dotted keys, mixed with objects.
+	input := `{"user": {"id": 15}, "user.name": "john", "tags": "foo", "opt": null}`
+	var d doc
+	assert.NilError(t, fields.Unmarshal([]byte(input), &d))
+	assert.Equal(t, "john", d.User.Value().Name.Value())
+	assert.Equal(t, 15, d.User.Value().ID)
+	assert.Assert(t, elastic.Equal(elastic.FromValue("foo"), d.Tags))
+	assert.Assert(t, d.Opt.IsNone())
+}
+
+func TestUnmarshal_error(t *testing.T) {
+	var d doc
+	err := fields.Unmarshal([]byte(`{"scalar": ["foo", "bar"]}`), &d)
+	assert.ErrorIs(t, err, fields.ErrMultipleValues)
+	err = fields.Unmarshal([]byte(`{"user": 1, "user.name": ["john"]}`), &d)
+	assert.ErrorIs(t, err, fields.ErrConflictingKeys)
+	err = fields.Unmarshal([]byte(`{}`), d)
+	assert.Assert(t, err != nil)
+}
+
+type (
+	caseInsensitive struct {
+		Foo und.Und[string]         `json:"foo"`
+		FOO elastic.Elastic[string] `json:"FOO"`
+		conflictA
+		*conflictB
+	}
+	conflictA struct {
+		Dup und.Und[string] `json:"dup"`
+	}
+	conflictB struct {
+		Dup und.Und[string] `json:"dup"`
+	}
+)
+
+func TestUnmarshal_field_match(t *testing.T) {
+	// matching is repeated since the result was once dependent on map iteration order.
+	for range 20 {
+		var v caseInsensitive
+		err := fields.Unmarshal([]byte(`{"Foo": ["foo"], "FOO": ["bar", "baz"], "dup": ["dup"]}`), &v)
+		assert.NilError(t, err)
+		// Foo matches the first field declared case-insensitively, and FOO matches exactly.
+		assert.Equal(t, "foo", v.Foo.Value())
+		assert.DeepEqual(t, []string{"bar", "baz"}, v.FOO.Values())
+		// conflicting embedded fields are ignored as encoding/json does.
+		assert.Assert(t, v.conflictA.Dup.IsUndefined())
+		assert.Assert(t, v.conflictB == nil)
+	}
+}
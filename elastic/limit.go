@@ -0,0 +1,95 @@
+package elastic
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ngicks/und/undtag"
+)
+
+var (
+	// ErrTooManyElements is wrapped in *LimitError
+	// if an input has more elements than DecodeLimits.MaxElements.
+	ErrTooManyElements = errors.New("too many elements")
+	// ErrTooLarge is wrapped in *LimitError
+	// if an input is larger than DecodeLimits.MaxBytes.
+	ErrTooLarge = errors.New("too large")
+	// ErrNullElement is wrapped in *LimitError
+	// if an input contains null while DecodeLimits.NonNull is set.
+	ErrNullElement = errors.New("null element")
+)
+
+// DecodeLimits configures safeguards against oversized or malformed inputs.
+// They are enforced while decoding, so that an offending input is rejected
+// before it is entirely materialized into an Elastic[T].
+//
+// DecodeLimits are enforced by [Elastic.UnmarshalJSONLimited] and [Elastic.UnmarshalXMLLimited].
+// [Elastic.UnmarshalJSON] and [Elastic.UnmarshalXML], hence json.Unmarshal and xml.Unmarshal, decode inputs entirely.
+//
+// To enforce limits placed by `und` struct tags on fields, decode structs by validate.UnmarshalJSON or validate.Decoder.
+// They reject Elastic fields exceeding upper bounds of len options, or having null elements while tagged values:nonnull,
+// before decoding the input.
+// For XML, call the limited methods from UnmarshalXML of an enclosing type,
+// deriving limits from the `und` struct tag by [DecodeLimitsFromOption].
+//
+// Zero value of each field means no limit.
+type DecodeLimits struct {
+	// MaxElements is the maximum number of elements.
+	// For XML, it also counts elements already stored by repeated elements.
+	// A negative value rejects any element.
+	MaxElements int
+	// MaxBytes is the maximum size of the encoded input in bytes.
+	MaxBytes int64
+	// NonNull rejects null elements.
+	NonNull bool
+}
+
+// DecodeLimitsFromOption derives DecodeLimits from `und` struct tag options.
+//
+// len==n, len<=n, len<n and len=m..n set MaxElements to n, n, n-1 and n respectively
+// (-1 if it only allows an empty Elastic).
+// values:nonnull sets NonNull.
+// Other options are ignored.
+func DecodeLimitsFromOption(opt undtag.UndOpt) DecodeLimits {
+	var limits DecodeLimits
+	if l, ok := opt.Len().Get(); ok {
+		if n, ok := l.UpperBound(); ok {
+			limits.MaxElements = n
+			if n <= 0 {
+				// only an empty Elastic is allowed.
+				limits.MaxElements = -1
+			}
+		}
+	}
+	if opt.Values().IsSome() {
+		limits.NonNull = opt.Values().Value().Nonnull
+	}
+	return limits
+}
+
+// LimitError describes an input violating DecodeLimits.
+type LimitError struct {
+	// Err is one of ErrTooManyElements, ErrTooLarge or ErrNullElement.
+	Err error
+	// Limit is the configured limit.
+	// Limit is 0 for ErrNullElement.
+	Limit int64
+	// Index is the index of the offending element,
+	// or -1 if the error is not about an element.
+	Index int
+}
+
+func (e *LimitError) Error() string {
+	switch {
+	case errors.Is(e.Err, ErrNullElement):
+		return fmt.Sprintf("elastic: %s at index %d", e.Err, e.Index)
+	case e.Index >= 0:
+		return fmt.Sprintf("elastic: %s at index %d: limit = %d", e.Err, e.Index, e.Limit)
+	default:
+		return fmt.Sprintf("elastic: %s: limit = %d", e.Err, e.Limit)
+	}
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,267 @@
+// Package mapping generates Elasticsearch index mappings from Go struct types.
+//
+// Since Elasticsearch fields are inherently multi-valued,
+// data container types, e.g. [elastic.Elastic], [und.Und] or [option.Option],
+// and slices or arrays are unwrapped and mapped as their element type.
+//
+// [elastic.Elastic]: https://pkg.go.dev/github.com/ngicks/und/elastic#Elastic
+// [und.Und]: https://pkg.go.dev/github.com/ngicks/und#Und
+// [option.Option]: https://pkg.go.dev/github.com/ngicks/und/option#Option
+package mapping
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/ngicks/und/internal/structfield"
+	"github.com/ngicks/und/undtag"
+)
+
+const (
+	// TagName is the struct tag key read by the generator.
+	//
+	// The tag value is comma-separated options.
+	//   - "-": the field is skipped.
+	//   - type=<type>: overrides the field type, e.g. type=text.
+	//   - nested: maps a struct element as the nested type instead of object.
+	//   - null_value=<value>: sets null_value. value must not contain comma.
+	//   - ignore_above=<n>: sets ignore_above.
+	//   - ignore_malformed: sets ignore_malformed to true.
+	//   - format=<format>: sets format, e.g. format=epoch_millis.
+	//
+	// example:
+	// type Sample struct {
+	// 	Foo elastic.Elastic[string] `json:"foo" es:"type=text"`
+	// 	Bar und.Und[string]         `json:"bar" es:"null_value=NULL,ignore_above=256"`
+	// 	Baz []Nested                `json:"baz" es:"nested"`
+	// }
+	TagName = "es"
+)
+
+var (
+	// ErrNotStruct is returned by [Generate] if the input type is not a struct nor a pointer to a struct.
+	ErrNotStruct = errors.New("not struct")
+	// ErrUnsupportedType is returned if a field type can not be mapped to any Elasticsearch field type
+	// and type option is not specified in the tag.
+	ErrUnsupportedType = errors.New("unsupported type")
+	// ErrRecursiveType is returned if a struct type contains itself.
+	ErrRecursiveType = errors.New("recursive type")
+	// ErrMalformedTag is returned if the `es` struct tag is malformed.
+	ErrMalformedTag = errors.New("malformed tag")
+)
+
+// Mapping is a mapping definition of an index.
+// It is encoded as the "mappings" field of the create index API request body.
+type Mapping struct {
+	Properties map[string]Property `json:"properties"`
+}
+
+// Property is a mapping definition of a field.
+type Property struct {
+	Type            string              `json:"type,omitempty"`
+	Format          string              `json:"format,omitempty"`
+	NullValue       json.RawMessage     `json:"null_value,omitempty"`
+	IgnoreAbove     int                 `json:"ignore_above,omitempty"`
+	IgnoreMalformed bool                `json:"ignore_malformed,omitempty"`
+	Properties      map[string]Property `json:"properties,omitempty"`
+}
+
+// For generates a mapping for T.
+// See [Generate].
+func For[T any]() (Mapping, error) {
+	return Generate(reflect.TypeFor[T]())
+}
+
+// Generate generates a mapping for rt.
+//
+// rt must be a struct type or a pointer to a struct type.
+// Field names are taken from `json` struct tags, falling back to Go field names.
+// Embedded structs are flattened as encoding/json does.
+func Generate(rt reflect.Type) (Mapping, error) {
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct {
+		return Mapping{}, fmt.Errorf("%w: input is expected to be a struct type but is %s", ErrNotStruct, rt.Kind())
+	}
+	props, err := properties(rt, map[reflect.Type]bool{})
+	if err != nil {
+		return Mapping{}, err
+	}
+	return Mapping{Properties: props}, nil
+}
+
+var (
+	undLikeTy    = reflect.TypeFor[undtag.UndLike]()
+	optionLikeTy = reflect.TypeFor[undtag.OptionLike]()
+	timeTy       = reflect.TypeFor[time.Time]()
+)
+
+func properties(rt reflect.Type, visited map[reflect.Type]bool) (map[string]Property, error) {
+	if visited[rt] {
+		return nil, fmt.Errorf("%w: %s", ErrRecursiveType, rt)
+	}
+	visited[rt] = true
+	defer delete(visited, rt)
+
+	props := make(map[string]Property)
+	// fields are flattened and conflicting names are resolved as encoding/json does.
+	for _, f := range structfield.Visible(rt, "json") {
+		skip, err := embeddedSkipped(rt, f.Index)
+		if err != nil {
+			return nil, err
+		}
+		if skip {
+			continue
+		}
+
+		tag, err := parseTag(f.Tag.Get(TagName))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.StructField.Name, err)
+		}
+		if tag.skip {
+			continue
+		}
+
+		prop, err := property(f.Type, tag, visited)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.StructField.Name, err)
+		}
+		props[f.Name] = prop
+	}
+	return props, nil
+}
+
+// embeddedSkipped reports whether the field at index of rt is promoted from an embedded struct tagged `es:"-"`.
+func embeddedSkipped(rt reflect.Type, index []int) (bool, error) {
+	for i := 1; i < len(index); i++ {
+		ft := rt.FieldByIndex(index[:i])
+		tag, err := parseTag(ft.Tag.Get(TagName))
+		if err != nil {
+			return false, fmt.Errorf("field %s: %w", ft.Name, err)
+		}
+		if tag.skip {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func property(rt reflect.Type, tag tagOption, visited map[reflect.Type]bool) (Property, error) {
+	rt = unwrap(rt)
+
+	prop := Property{
+		Type:            tag.ty,
+		Format:          tag.format,
+		IgnoreAbove:     tag.ignoreAbove,
+		IgnoreMalformed: tag.ignoreMalformed,
+	}
+
+	if prop.Type == "" {
+		var err error
+		prop.Type, err = fieldType(rt)
+		if err != nil {
+			return Property{}, err
+		}
+	}
+	if tag.nested {
+		prop.Type = "nested"
+	}
+
+	if (prop.Type == "object" || prop.Type == "nested") && rt.Kind() == reflect.Struct {
+		props, err := properties(rt, visited)
+		if err != nil {
+			return Property{}, err
+		}
+		prop.Properties = props
+	}
+
+	if tag.nullValue.IsSome() {
+		nullValue, err := encodeNullValue(prop.Type, tag.nullValue.Value())
+		if err != nil {
+			return Property{}, err
+		}
+		prop.NullValue = nullValue
+	}
+
+	return prop, nil
+}
+
+// unwrap unwraps pointers, slices, arrays and data container types down to their element type.
+func unwrap(rt reflect.Type) reflect.Type {
+	for {
+		switch {
+		case isByteSlice(rt):
+			return rt
+		case rt.Implements(undLikeTy) || rt.Implements(optionLikeTy):
+			m, ok := rt.MethodByName("Value")
+			if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 {
+				return rt
+			}
+			rt = m.Type.Out(0)
+		case rt.Kind() == reflect.Pointer, rt.Kind() == reflect.Slice, rt.Kind() == reflect.Array:
+			rt = rt.Elem()
+		default:
+			return rt
+		}
+	}
+}
+
+// isByteSlice reports whether rt is a slice of bytes, e.g. []byte or json.RawMessage,
+// which encoding/json encodes as a string.
+func isByteSlice(rt reflect.Type) bool {
+	return rt.Kind() == reflect.Slice && rt.Elem().Kind() == reflect.Uint8
+}
+
+func fieldType(rt reflect.Type) (string, error) {
+	switch {
+	case rt == timeTy:
+		return "date", nil
+	case isByteSlice(rt):
+		return "binary", nil
+	}
+	switch rt.Kind() {
+	case reflect.String:
+		return "keyword", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int8:
+		return "byte", nil
+	case reflect.Int16, reflect.Uint8:
+		return "short", nil
+	case reflect.Int32, reflect.Uint16:
+		return "integer", nil
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "long", nil
+	case reflect.Uint, reflect.Uint64:
+		return "unsigned_long", nil
+	case reflect.Float32:
+		return "float", nil
+	case reflect.Float64:
+		return "double", nil
+	case reflect.Struct, reflect.Map:
+		return "object", nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedType, rt)
+}
+
+func encodeNullValue(ty string, v string) (json.RawMessage, error) {
+	switch ty {
+	case "keyword", "text", "date", "ip", "wildcard", "constant_keyword":
+		return json.Marshal(v)
+	case "boolean":
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: null_value for boolean: %w", ErrMalformedTag, err)
+		}
+		return json.Marshal(b)
+	}
+	if !json.Valid([]byte(v)) {
+		return nil, fmt.Errorf("%w: null_value for %s is not a valid JSON value: %s", ErrMalformedTag, ty, v)
+	}
+	return json.RawMessage(v), nil
+}
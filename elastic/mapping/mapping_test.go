@@ -0,0 +1,144 @@
+package mapping_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ngicks/und"
+	"github.com/ngicks/und/elastic"
+	"github.com/ngicks/und/elastic/mapping"
+	"github.com/ngicks/und/option"
+	"github.com/ngicks/und/sliceund"
+	sliceelastic "github.com/ngicks/und/sliceund/elastic"
+	"gotest.tools/v3/assert"
+)
+
+type sample struct {
+	Embedded
+	Keyword  string                     `json:"keyword"`
+	Text     elastic.Elastic[string]    `json:"text" es:"type=text"`
+	Long     und.Und[int]               `json:"long,omitzero" es:"null_value=-1"`
+	Double   sliceund.Und[float64]      `json:"double,omitempty"`
+	Bool     sliceelastic.Elastic[bool] `json:"bool,omitempty" es:"null_value=false"`
+	Date     option.Option[time.Time]   `json:"date" es:"format=strict_date_optional_time"`
+	Multi    elastic.Elastic[[]int16]   `json:"multi"`
+	Null     und.Und[string]            `json:"null" es:"null_value=NULL,ignore_above=256"`
+	Obj      und.Und[nested]            `json:"obj"`
+	Nested   elastic.Elastic[nested]    `json:"nested" es:"nested"`
+	Binary   []byte                     `json:"binary"`
+	Ptr      *int8                      `json:"ptr" es:"ignore_malformed"`
+	NoTag    string
+	Skipped  string `json:"-"`
+	Skipped2 string `es:"-"`
+	private  string
+}
+
+type Embedded struct {
+	Inner string `json:"inner"`
+}
+
+type nested struct {
+	Foo string `json:"foo"`
+}
+
+func TestGenerate(t *testing.T) {
+	m, err := mapping.For[sample]()
+	assert.NilError(t, err)
+
+	bin, err := json.Marshal(m)
+	assert.NilError(t, err)
+
+	expected := `{"properties":{` +
+		`"NoTag":{"type":"keyword"},` +
+		`"binary":{"type":"binary"},` +
+		`"bool":{"type":"boolean","null_value":false},` +
+		`"date":{"type":"date","format":"strict_date_optional_time"},` +
+		`"double":{"type":"double"},` +
+		`"inner":{"type":"keyword"},` +
+		`"keyword":{"type":"keyword"},` +
+		`"long":{"type":"long","null_value":-1},` +
+		`"multi":{"type":"short"},` +
+		`"nested":{"type":"nested","properties":{"foo":{"type":"keyword"}}},` +
+		`"null":{"type":"keyword","null_value":"NULL","ignore_above":256},` +
+		`"obj":{"type":"object","properties":{"foo":{"type":"keyword"}}},` +
+		`"ptr":{"type":"byte","ignore_malformed":true},` +
+		`"text":{"type":"text"}` +
+		`}}`
+	assert.Equal(t, expected, string(bin))
+}
+
+type recursive struct {
+	Self und.Und[*recursive]
+}
+
+type unsupported struct {
+	Any any
+}
+
+type malformed struct {
+	Foo string `es:"ignore_above=foo"`
+}
+
+func TestGenerate_error(t *testing.T) {
+	var err error
+	_, err = mapping.For[int]()
+	assert.ErrorIs(t, err, mapping.ErrNotStruct)
+	_, err = mapping.For[recursive]()
+	assert.ErrorIs(t, err, mapping.ErrRecursiveType)
+	_, err = mapping.For[unsupported]()
+	assert.ErrorIs(t, err, mapping.ErrUnsupportedType)
+	_, err = mapping.For[malformed]()
+	assert.ErrorIs(t, err, mapping.ErrMalformedTag)
+}
+
+type (
+	rawBytes []byte
+	binaries struct {
+		Raw   json.RawMessage                  `json:"raw"`
+		Named rawBytes                         `json:"named"`
+		Elem  elastic.Elastic[json.RawMessage] `json:"elem"`
+	}
+)
+
+func TestGenerate_byte_slices(t *testing.T) {
+	m, err := mapping.For[binaries]()
+	assert.NilError(t, err)
+	bin, err := json.Marshal(m)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"properties":{"elem":{"type":"binary"},"named":{"type":"binary"},"raw":{"type":"binary"}}}`, string(bin))
+}
+
+type (
+	conflictA struct {
+		Dup    string `json:"dup"`
+		Tagged string `json:"Tagged"`
+		A      string `json:"a"`
+	}
+	conflictB struct {
+		Dup    int    `json:"dup"`
+		Tagged string // Tagged of conflictA wins.
+		B      string `json:"b"`
+	}
+	conflictSkipped struct {
+		Skipped string `json:"skipped"`
+	}
+	conflicts struct {
+		conflictA
+		*conflictB
+		conflictSkipped `es:"-"`
+	}
+)
+
+func TestGenerate_embedded_conflict(t *testing.T) {
+	m, err := mapping.For[conflicts]()
+	assert.NilError(t, err)
+	bin, err := json.Marshal(m)
+	assert.NilError(t, err)
+	// dup is dropped as encoding/json does.
+	assert.Equal(t, `{"properties":{"Tagged":{"type":"keyword"},"a":{"type":"keyword"},"b":{"type":"keyword"}}}`, string(bin))
+
+	encoded, err := json.Marshal(conflicts{conflictA: conflictA{Dup: "x"}, conflictB: &conflictB{Dup: 1}})
+	assert.NilError(t, err)
+	assert.Equal(t, `{"Tagged":"","a":"","b":"","skipped":""}`, string(encoded))
+}
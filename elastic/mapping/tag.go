@@ -0,0 +1,66 @@
+package mapping
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ngicks/und/option"
+)
+
+type tagOption struct {
+	skip            bool
+	ty              string
+	nested          bool
+	format          string
+	nullValue       option.Option[string]
+	ignoreAbove     int
+	ignoreMalformed bool
+}
+
+func parseTag(s string) (tagOption, error) {
+	var opt tagOption
+	if s == "" {
+		return opt, nil
+	}
+	if s == "-" {
+		opt.skip = true
+		return opt, nil
+	}
+	for len(s) > 0 {
+		var o string
+		o, s, _ = strings.Cut(s, ",")
+		key, value, hasValue := strings.Cut(o, "=")
+		switch key {
+		case "type":
+			opt.ty = value
+		case "format":
+			opt.format = value
+		case "null_value":
+			opt.nullValue = option.Some(value)
+		case "ignore_above":
+			n, err := strconv.ParseUint(value, 10, 31)
+			if err != nil {
+				return tagOption{}, fmt.Errorf("%w: ignore_above: %w", ErrMalformedTag, err)
+			}
+			opt.ignoreAbove = int(n)
+		case "nested":
+			opt.nested = true
+		case "ignore_malformed":
+			opt.ignoreMalformed = true
+		default:
+			return tagOption{}, fmt.Errorf("%w: unknown option %q", ErrMalformedTag, key)
+		}
+		switch key {
+		case "nested", "ignore_malformed":
+			if hasValue {
+				return tagOption{}, fmt.Errorf("%w: %s takes no value", ErrMalformedTag, key)
+			}
+		default:
+			if !hasValue || (key != "null_value" && value == "") {
+				return tagOption{}, fmt.Errorf("%w: %s needs a value", ErrMalformedTag, key)
+			}
+		}
+	}
+	return opt, nil
+}
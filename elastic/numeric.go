@@ -0,0 +1,8 @@
+package elastic
+
+// Numeric is a constraint that permits any integer or floating-point type.
+type Numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
@@ -0,0 +1,120 @@
+package elastic
+
+import (
+	"bytes"
+	"encoding/json"
+	"iter"
+
+	"github.com/ngicks/und/option"
+)
+
+// DecodeJSONSeq reads an Elastic[T] value from dec and returns an iterator over its elements
+// without materializing the whole value.
+// dec must be positioned at the value, e.g. right after an object key is read by dec.Token.
+//
+// If the value is an array, seq decodes and yields elements one by one.
+// If the value is a single T, seq yields it as a one-element sequence.
+// If the value is null, DecodeJSONSeq returns null = true and seq yields nothing.
+//
+// seq reads from dec as it is iterated, thus it can be iterated only once.
+// When seq encounters an error, it yields the error and stops.
+// If iteration is stopped before seq is exhausted, dec is left in the middle of the array.
+//
+// When T is a slice or array type, an input array is always treated as array of T.
+// Use [Elastic.UnmarshalJSON] instead if the input can be a single T.
+//
+// If the first byte of the value is not buffered in dec yet, which only happens at the boundary of the buffer of dec,
+// DecodeJSONSeq reads the entire value by dec.Decode before decoding it. Results are same,
+// but an array is then not streamed.
+func DecodeJSONSeq[T any](dec *json.Decoder) (seq iter.Seq2[option.Option[T], error], null bool, err error) {
+	c, ok := peekJSON(dec)
+	if !ok {
+		// Could not look ahead without consuming.
+		// This only happens on buffer boundary or errors.
+		return decodeJSONSeqBuffered[T](dec)
+	}
+
+	switch c {
+	case 'n':
+		if _, err := dec.Token(); err != nil {
+			return nil, false, err
+		}
+		return func(yield func(option.Option[T], error) bool) {}, true, nil
+	case '[':
+		if _, err := dec.Token(); err != nil {
+			return nil, false, err
+		}
+		return decodeElements[T](dec), false, nil
+	default:
+		return func(yield func(option.Option[T], error) bool) {
+			var opt option.Option[T]
+			err := dec.Decode(&opt)
+			yield(opt, err)
+		}, false, nil
+	}
+}
+
+// decodeElements decodes elements of an array whose '[' is already consumed from dec.
+func decodeElements[T any](dec *json.Decoder) iter.Seq2[option.Option[T], error] {
+	return func(yield func(option.Option[T], error) bool) {
+		for dec.More() {
+			var opt option.Option[T]
+			if err := dec.Decode(&opt); err != nil {
+				yield(option.None[T](), err)
+				return
+			}
+			if !yield(opt, nil) {
+				return
+			}
+		}
+		if _, err := dec.Token(); err != nil { // consumes ']'
+			yield(option.None[T](), err)
+		}
+	}
+}
+
+// decodeJSONSeqBuffered is [DecodeJSONSeq] which reads the entire value by dec.Decode.
+// Values are decoded in the same way as DecodeJSONSeq does with the value buffered in dec:
+// arrays are decoded element by element, and others as a single Option[T].
+func decodeJSONSeqBuffered[T any](dec *json.Decoder) (seq iter.Seq2[option.Option[T], error], null bool, err error) {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, false, err
+	}
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case string(raw) == "null":
+		return func(yield func(option.Option[T], error) bool) {}, true, nil
+	case raw[0] == '[':
+		elemDec := json.NewDecoder(bytes.NewReader(raw))
+		if _, err := elemDec.Token(); err != nil {
+			return nil, false, err
+		}
+		return decodeElements[T](elemDec), false, nil
+	}
+	return func(yield func(option.Option[T], error) bool) {
+		var opt option.Option[T]
+		err := json.Unmarshal(raw, &opt)
+		yield(opt, err)
+	}, false, nil
+}
+
+// peekJSON returns the first byte of the next value of dec without consuming it.
+// ok is false if it could not be determined from data buffered in dec.
+func peekJSON(dec *json.Decoder) (c byte, ok bool) {
+	// More fills the buffer at least to the next non-space byte.
+	_ = dec.More()
+	r := dec.Buffered()
+	var b [1]byte
+	for {
+		if n, _ := r.Read(b[:]); n == 0 {
+			return 0, false
+		}
+		switch b[0] {
+		// separators are consumed by dec itself before decoding the next value.
+		case ' ', '\t', '\r', '\n', ',', ':':
+			continue
+		}
+		return b[0], true
+	}
+}
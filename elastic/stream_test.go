@@ -0,0 +1,216 @@
+package elastic
+
+import (
+	"encoding/json"
+	"io"
+	"iter"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/ngicks/und/option"
+	"gotest.tools/v3/assert"
+)
+
+func collectSeq[T any](t *testing.T, dec *json.Decoder) ([]option.Option[T], bool) {
+	t.Helper()
+	seq, null, err := DecodeJSONSeq[T](dec)
+	assert.NilError(t, err)
+	var opts []option.Option[T]
+	for opt, err := range seq {
+		assert.NilError(t, err)
+		opts = append(opts, opt)
+	}
+	return opts, null
+}
+
+func TestDecodeJSONSeq(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`{"a": ["foo", null, "bar"], "b": "baz", "c": null, "d": []}`))
+	var (
+		opts []option.Option[string]
+		null bool
+	)
+
+	_, _ = dec.Token() // {
+	_, _ = dec.Token() // "a"
+	opts, null = collectSeq[string](t, dec)
+	assert.Assert(t, !null)
+	assert.Assert(t, option.EqualOptions(opts, []option.Option[string]{option.Some("foo"), option.None[string](), option.Some("bar")}))
+
+	_, _ = dec.Token() // "b"
+	opts, null = collectSeq[string](t, dec)
+	assert.Assert(t, !null)
+	assert.Assert(t, option.EqualOptions(opts, []option.Option[string]{option.Some("baz")}))
+
+	_, _ = dec.Token() // "c"
+	opts, null = collectSeq[string](t, dec)
+	assert.Assert(t, null)
+	assert.Equal(t, 0, len(opts))
+
+	_, _ = dec.Token() // "d"
+	opts, null = collectSeq[string](t, dec)
+	assert.Assert(t, !null)
+	assert.Equal(t, 0, len(opts))
+
+	tok, err := dec.Token()
+	assert.NilError(t, err)
+	assert.Equal(t, json.Delim('}'), tok)
+	_, err = dec.Token()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestDecodeJSONSeq_object(t *testing.T) {
+	type sample struct {
+		Foo string
+	}
+	dec := json.NewDecoder(strings.NewReader(`{"Foo":"foo"} [{"Foo":"bar"},null]`))
+
+	opts, _ := collectSeq[sample](t, dec)
+	assert.Assert(t, option.EqualOptions(opts, []option.Option[sample]{option.Some(sample{"foo"})}))
+	opts, _ = collectSeq[sample](t, dec)
+	assert.Assert(t, option.EqualOptions(opts, []option.Option[sample]{option.Some(sample{"bar"}), option.None[sample]()}))
+}
+
+func TestDecodeJSONSeq_error(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`["foo", 1]`))
+	seq, _, err := DecodeJSONSeq[string](dec)
+	assert.NilError(t, err)
+	var errs []error
+	for _, err := range seq {
+		errs = append(errs, err)
+	}
+	assert.Equal(t, 2, len(errs))
+	assert.NilError(t, errs[0])
+	assert.Assert(t, errs[1] != nil)
+
+	dec = json.NewDecoder(strings.NewReader(``))
+	_, _, err = DecodeJSONSeq[string](dec)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestDecodeJSONSeq_buffer_boundary(t *testing.T) {
+	// the value might not be looked ahead; then it is read entirely.
+	dec := json.NewDecoder(iotest.OneByteReader(strings.NewReader(`{"a":["foo",null],"b":"bar","c":null,"d":{"Foo":"baz","Bar":[1,{"x":true}]}}`)))
+	_, _ = dec.Token() // {
+	_, _ = dec.Token() // "a"
+	opts, null := collectSeq[string](t, dec)
+	assert.Assert(t, !null)
+	assert.Assert(t, option.EqualOptions(opts, []option.Option[string]{option.Some("foo"), option.None[string]()}))
+
+	_, _ = dec.Token() // "b"
+	opts, null = collectSeq[string](t, dec)
+	assert.Assert(t, !null)
+	assert.Assert(t, option.EqualOptions(opts, []option.Option[string]{option.Some("bar")}))
+
+	_, _ = dec.Token() // "c"
+	opts, null = collectSeq[string](t, dec)
+	assert.Assert(t, null)
+	assert.Equal(t, 0, len(opts))
+
+	type sample struct {
+		Foo string
+		Bar []any
+	}
+	_, _ = dec.Token() // "d"
+	objs, null := collectSeq[sample](t, dec)
+	assert.Assert(t, !null)
+	assert.Equal(t, 1, len(objs))
+	assert.DeepEqual(t, sample{Foo: "baz", Bar: []any{float64(1), map[string]any{"x": true}}}, objs[0].Value())
+
+	tok, err := dec.Token()
+	assert.NilError(t, err)
+	assert.Equal(t, json.Delim('}'), tok)
+}
+
+func TestDecodeJSONSeq_larger_than_buffer(t *testing.T) {
+	// the value starts within the buffer of dec; see TestDecodeJSONSeq_buffer_boundary for other cases.
+	input := `{"a":[` + strings.Repeat(`"foo",`, 1<<16) + `"bar"]}`
+	r := &countingReader{r: strings.NewReader(input)}
+	dec := json.NewDecoder(r)
+	_, _ = dec.Token() // {
+	_, _ = dec.Token() // "a"
+	seq, null, err := DecodeJSONSeq[string](dec)
+	assert.NilError(t, err)
+	assert.Assert(t, !null)
+	var n int
+	for opt, err := range seq {
+		assert.NilError(t, err)
+		assert.Equal(t, "foo", opt.Value())
+		n++
+		if n == 10 {
+			break
+		}
+	}
+	// elements are decoded without reading the entire input.
+	assert.Assert(t, r.n < len(input)/2, "read %d of %d bytes", r.n, len(input))
+}
+
+func TestDecodeJSONSeq_buffered(t *testing.T) {
+	// Whether the value is buffered depends on the implementation of json.Decoder.
+	// Test the path for unbuffered values directly.
+	collect := func(dec *json.Decoder) ([]option.Option[any], bool, error) {
+		return collectSeqFunc(decodeJSONSeqBuffered[any], dec)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(
+		`null ["foo",null,{"a":[1,2]}] "bar" 1.5 true {} {"a":{"b":[[],{}],"c":null},"d":"\u0000\"e"}`,
+	))
+	opts, null, err := collect(dec)
+	assert.NilError(t, err)
+	assert.Assert(t, null)
+	assert.Equal(t, 0, len(opts))
+	for _, expected := range [][]option.Option[any]{
+		{option.Some[any]("foo"), option.None[any](), option.Some[any](map[string]any{"a": []any{float64(1), float64(2)}})},
+		{option.Some[any]("bar")},
+		{option.Some[any](1.5)},
+		{option.Some[any](true)},
+		{option.Some[any](map[string]any{})},
+		{option.Some[any](map[string]any{"a": map[string]any{"b": []any{[]any{}, map[string]any{}}, "c": nil}, "d": "\x00\"e"})},
+	} {
+		opts, null, err := collect(dec)
+		assert.NilError(t, err)
+		assert.Assert(t, !null)
+		assert.Assert(t, reflect.DeepEqual(expected, opts), "expected = %#v, actual = %#v", expected, opts)
+	}
+	_, _, err = collect(dec)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestDecodeJSONSeq_buffered_values(t *testing.T) {
+	// values are decoded from the input as is, as DecodeJSONSeq does with buffered values.
+	dec := json.NewDecoder(strings.NewReader(`9007199254740993 1e20 {"a" : "\u00e9"} [{"a" : 1}]`))
+
+	ints, _, err := collectSeqFunc(decodeJSONSeqBuffered[int64], dec)
+	assert.NilError(t, err)
+	assert.Equal(t, int64(9007199254740993), ints[0].Value())
+
+	floats, _, err := collectSeqFunc(decodeJSONSeqBuffered[float64], dec)
+	assert.NilError(t, err)
+	assert.Equal(t, 1e20, floats[0].Value())
+
+	for _, expected := range []string{`{"a" : "\u00e9"}`, `{"a" : 1}`} {
+		raws, _, err := collectSeqFunc(decodeJSONSeqBuffered[json.RawMessage], dec)
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(raws))
+		assert.Equal(t, expected, string(raws[0].Value()))
+	}
+}
+
+func collectSeqFunc[T any](
+	decode func(dec *json.Decoder) (iter.Seq2[option.Option[T], error], bool, error),
+	dec *json.Decoder,
+) ([]option.Option[T], bool, error) {
+	seq, null, err := decode(dec)
+	if err != nil {
+		return nil, null, err
+	}
+	var opts []option.Option[T]
+	for opt, err := range seq {
+		if err != nil {
+			return nil, null, err
+		}
+		opts = append(opts, opt)
+	}
+	return opts, null, nil
+}
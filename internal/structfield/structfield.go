@@ -0,0 +1,177 @@
+// Package structfield lists fields of structs as encoding/json sees them.
+package structfield
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Field is a field of a struct as the encoder sees it.
+type Field struct {
+	reflect.StructField
+	// Name is the name of the field read from the name tag, falling back to the Go field name.
+	Name string
+	// Index is the index sequence for reflect.Value.FieldByIndex.
+	// len(Index) > 1 if the field is promoted from embedded structs.
+	Index []int
+	// Tagged is true if Name is read from the name tag.
+	Tagged bool
+}
+
+// Visible lists fields of rt, a struct type, following the rules of encoding/json.
+//
+// Fields of embedded structs without name in the nameTag struct tag are promoted into the outer struct.
+// Fields tagged with the inline option, e.g. `yaml:",inline"`, are promoted as well.
+// If multiple fields have the same name, the shallowest one wins.
+// Among fields at the same depth, the only tagged one wins, or all of them are dropped otherwise.
+//
+// Fields are returned in the order of declaration, where promoted fields take the place of the embedded struct.
+func Visible(rt reflect.Type, nameTag string) []Field {
+	type embedded struct {
+		rt    reflect.Type
+		index []int
+	}
+
+	var (
+		fields    []Field
+		current   []embedded
+		next      = []embedded{{rt: rt}}
+		count     map[reflect.Type]int
+		nextCount = map[reflect.Type]int{}
+		visited   = map[reflect.Type]bool{}
+	)
+	for len(next) > 0 {
+		current, next = next, nil
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, e := range current {
+			if visited[e.rt] {
+				continue
+			}
+			visited[e.rt] = true
+
+			for i := 0; i < e.rt.NumField(); i++ {
+				sf := e.rt.Field(i)
+
+				tag := sf.Tag.Get(nameTag)
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+
+				inline := ft.Kind() == reflect.Struct && (sf.Anonymous && name == "" || HasOption(opts, "inline"))
+				if sf.Anonymous {
+					// As well as encoding/json, exported fields of an embedded struct of unexported type are visible.
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				index := append(slices.Clone(e.index), i)
+
+				if !inline {
+					f := Field{StructField: sf, Name: name, Index: index, Tagged: name != ""}
+					if f.Name == "" {
+						f.Name = sf.Name
+					}
+					fields = append(fields, f)
+					if count[e.rt] > 1 {
+						// The struct is embedded multiple times at the same depth.
+						// Add the field twice so that it is annihilated by the dominance rule.
+						fields = append(fields, f)
+					}
+					continue
+				}
+
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, embedded{rt: ft, index: index})
+				}
+			}
+		}
+	}
+
+	slices.SortStableFunc(fields, func(i, j Field) int {
+		if c := strings.Compare(i.Name, j.Name); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(i.Index), len(j.Index)); c != 0 {
+			return c
+		}
+		if i.Tagged != j.Tagged {
+			if i.Tagged {
+				return -1
+			}
+			return 1
+		}
+		return slices.Compare(i.Index, j.Index)
+	})
+
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		fi := fields[i]
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].Name != fi.Name {
+				break
+			}
+		}
+		if advance == 1 {
+			out = append(out, fi)
+			continue
+		}
+		if dominant, ok := dominantField(fields[i : i+advance]); ok {
+			out = append(out, dominant)
+		}
+	}
+
+	slices.SortFunc(out, func(i, j Field) int {
+		return slices.Compare(i.Index, j.Index)
+	})
+	return out
+}
+
+// dominantField returns the field winning among fields having the same name.
+// fields must be sorted by depth and then tagged ones first.
+func dominantField(fields []Field) (Field, bool) {
+	if len(fields) > 1 && len(fields[0].Index) == len(fields[1].Index) && fields[0].Tagged == fields[1].Tagged {
+		return Field{}, false
+	}
+	return fields[0], true
+}
+
+// Match finds the field for key as encoding/json does; exact match is preferred over case-insensitive match.
+// Among fields matching case-insensitively, the first one in fields wins.
+func Match(fields []Field, key string) (Field, bool) {
+	for _, f := range fields {
+		if f.Name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.Name, key) {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// HasOption reports whether opts, comma separated options in a struct tag, contains opt.
+func HasOption(opts string, opt string) bool {
+	for len(opts) > 0 {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
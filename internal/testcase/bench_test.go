@@ -0,0 +1,227 @@
+package testcase_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ngicks/und"
+	"github.com/ngicks/und/elastic"
+	"github.com/ngicks/und/option"
+	"github.com/ngicks/und/sliceund"
+	sliceelastic "github.com/ngicks/und/sliceund/elastic"
+)
+
+// Benchmarks for all container types.
+// They are placed in a single file so that regressions in one variant can be easily compared against others.
+
+var (
+	sinkBool  bool
+	sinkBytes []byte
+	sinkErr   error
+)
+
+func benchmarkConstruction[T any](b *testing.B, fns map[string]func() T) {
+	for _, name := range []string{"defined", "null", "undefined"} {
+		fn, ok := fns[name]
+		if !ok {
+			continue
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			var sink T
+			for range b.N {
+				sink = fn()
+			}
+			_ = sink
+		})
+	}
+}
+
+func benchmarkMarshalJSON[T any](b *testing.B, values map[string]T) {
+	for _, name := range []string{"defined", "null", "undefined"} {
+		v, ok := values[name]
+		if !ok {
+			continue
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				sinkBytes, sinkErr = json.Marshal(v)
+			}
+		})
+	}
+}
+
+func benchmarkUnmarshalJSON[T any](b *testing.B, inputs map[string]string) {
+	for _, name := range []string{"defined", "null", "array"} {
+		input, ok := inputs[name]
+		if !ok {
+			continue
+		}
+		bin := []byte(input)
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				var t T
+				sinkErr = json.Unmarshal(bin, &t)
+			}
+		})
+	}
+}
+
+func benchmarkClone[T any](b *testing.B, values map[string]T, clone func(T) T) {
+	for _, name := range []string{"defined", "null", "undefined"} {
+		v, ok := values[name]
+		if !ok {
+			continue
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			var sink T
+			for range b.N {
+				sink = clone(v)
+			}
+			_ = sink
+		})
+	}
+}
+
+func benchmarkEqualFunc[T any](b *testing.B, values map[string]T, equal func(l, r T) bool) {
+	for _, name := range []string{"defined", "null", "undefined"} {
+		v, ok := values[name]
+		if !ok {
+			continue
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				sinkBool = equal(v, v)
+			}
+		})
+	}
+}
+
+func cmpString(i, j string) bool { return i == j }
+
+func cloneString(s string) string { return s }
+
+func BenchmarkOption(b *testing.B) {
+	values := map[string]option.Option[string]{
+		"defined": option.Some("foo"),
+		"null":    option.None[string](),
+	}
+	b.Run("construction", func(b *testing.B) {
+		benchmarkConstruction(b, map[string]func() option.Option[string]{
+			"defined": func() option.Option[string] { return option.Some("foo") },
+			"null":    option.None[string],
+		})
+	})
+	b.Run("MarshalJSON", func(b *testing.B) { benchmarkMarshalJSON(b, values) })
+	b.Run("UnmarshalJSON", func(b *testing.B) {
+		benchmarkUnmarshalJSON[option.Option[string]](b, map[string]string{"defined": `"foo"`, "null": `null`})
+	})
+	b.Run("Clone", func(b *testing.B) {
+		benchmarkClone(b, values, func(o option.Option[string]) option.Option[string] { return o.CloneFunc(cloneString) })
+	})
+	b.Run("EqualFunc", func(b *testing.B) {
+		benchmarkEqualFunc(b, values, func(l, r option.Option[string]) bool { return l.EqualFunc(r, cmpString) })
+	})
+}
+
+func BenchmarkUnd(b *testing.B) {
+	values := map[string]und.Und[string]{
+		"defined":   und.Defined("foo"),
+		"null":      und.Null[string](),
+		"undefined": und.Undefined[string](),
+	}
+	b.Run("construction", func(b *testing.B) {
+		benchmarkConstruction(b, map[string]func() und.Und[string]{
+			"defined":   func() und.Und[string] { return und.Defined("foo") },
+			"null":      und.Null[string],
+			"undefined": und.Undefined[string],
+		})
+	})
+	b.Run("MarshalJSON", func(b *testing.B) { benchmarkMarshalJSON(b, values) })
+	b.Run("UnmarshalJSON", func(b *testing.B) {
+		benchmarkUnmarshalJSON[und.Und[string]](b, map[string]string{"defined": `"foo"`, "null": `null`})
+	})
+	b.Run("Clone", func(b *testing.B) { benchmarkClone(b, values, und.Clone[string]) })
+	b.Run("EqualFunc", func(b *testing.B) {
+		benchmarkEqualFunc(b, values, func(l, r und.Und[string]) bool { return l.EqualFunc(r, cmpString) })
+	})
+}
+
+func BenchmarkSliceUnd(b *testing.B) {
+	values := map[string]sliceund.Und[string]{
+		"defined":   sliceund.Defined("foo"),
+		"null":      sliceund.Null[string](),
+		"undefined": sliceund.Undefined[string](),
+	}
+	b.Run("construction", func(b *testing.B) {
+		benchmarkConstruction(b, map[string]func() sliceund.Und[string]{
+			"defined":   func() sliceund.Und[string] { return sliceund.Defined("foo") },
+			"null":      sliceund.Null[string],
+			"undefined": sliceund.Undefined[string],
+		})
+	})
+	b.Run("MarshalJSON", func(b *testing.B) { benchmarkMarshalJSON(b, values) })
+	b.Run("UnmarshalJSON", func(b *testing.B) {
+		benchmarkUnmarshalJSON[sliceund.Und[string]](b, map[string]string{"defined": `"foo"`, "null": `null`})
+	})
+	b.Run("Clone", func(b *testing.B) { benchmarkClone(b, values, sliceund.Clone[string]) })
+	b.Run("EqualFunc", func(b *testing.B) {
+		benchmarkEqualFunc(b, values, func(l, r sliceund.Und[string]) bool { return l.EqualFunc(r, cmpString) })
+	})
+}
+
+func BenchmarkElastic(b *testing.B) {
+	values := map[string]elastic.Elastic[string]{
+		"defined":   elastic.FromOptions(option.Some("foo"), option.None[string](), option.Some("bar")),
+		"null":      elastic.Null[string](),
+		"undefined": elastic.Undefined[string](),
+	}
+	b.Run("construction", func(b *testing.B) {
+		benchmarkConstruction(b, map[string]func() elastic.Elastic[string]{
+			"defined":   func() elastic.Elastic[string] { return elastic.FromValue("foo") },
+			"null":      elastic.Null[string],
+			"undefined": elastic.Undefined[string],
+		})
+	})
+	b.Run("MarshalJSON", func(b *testing.B) { benchmarkMarshalJSON(b, values) })
+	b.Run("UnmarshalJSON", func(b *testing.B) {
+		benchmarkUnmarshalJSON[elastic.Elastic[string]](
+			b,
+			map[string]string{"defined": `"foo"`, "null": `null`, "array": `["foo",null,"bar"]`},
+		)
+	})
+	b.Run("Clone", func(b *testing.B) { benchmarkClone(b, values, elastic.Clone[string]) })
+	b.Run("EqualFunc", func(b *testing.B) {
+		benchmarkEqualFunc(b, values, func(l, r elastic.Elastic[string]) bool { return l.EqualFunc(r, cmpString) })
+	})
+}
+
+func BenchmarkSliceElastic(b *testing.B) {
+	values := map[string]sliceelastic.Elastic[string]{
+		"defined":   sliceelastic.FromOptions(option.Some("foo"), option.None[string](), option.Some("bar")),
+		"null":      sliceelastic.Null[string](),
+		"undefined": sliceelastic.Undefined[string](),
+	}
+	b.Run("construction", func(b *testing.B) {
+		benchmarkConstruction(b, map[string]func() sliceelastic.Elastic[string]{
+			"defined":   func() sliceelastic.Elastic[string] { return sliceelastic.FromValue("foo") },
+			"null":      sliceelastic.Null[string],
+			"undefined": sliceelastic.Undefined[string],
+		})
+	})
+	b.Run("MarshalJSON", func(b *testing.B) { benchmarkMarshalJSON(b, values) })
+	b.Run("UnmarshalJSON", func(b *testing.B) {
+		benchmarkUnmarshalJSON[sliceelastic.Elastic[string]](
+			b,
+			map[string]string{"defined": `"foo"`, "null": `null`, "array": `["foo",null,"bar"]`},
+		)
+	})
+	b.Run("Clone", func(b *testing.B) { benchmarkClone(b, values, sliceelastic.Clone[string]) })
+	b.Run("EqualFunc", func(b *testing.B) {
+		benchmarkEqualFunc(b, values, func(l, r sliceelastic.Elastic[string]) bool { return l.EqualFunc(r, cmpString) })
+	})
+}
@@ -11,7 +11,7 @@ import (
 func (u Und[T]) Iter() iter.Seq[option.Option[T]] {
 	return func(yield func(option.Option[T]) bool) {
 		if !u.IsUndefined() {
-			yield(u.opt.Value())
+			yield(u.inner())
 		}
 	}
 }
@@ -0,0 +1,51 @@
+package elastic
+
+import (
+	"cmp"
+	"strconv"
+	"testing"
+
+	"github.com/ngicks/und/option"
+	"gotest.tools/v3/assert"
+)
+
+// portable tests that can be copied from github.com/ngicks/und/elastic into github.com/ngicks/und/sliceund/elastic
+
+func TestAggregate_empty(t *testing.T) {
+	for _, e := range []Elastic[int]{
+		Undefined[int](),
+		Null[int](),
+		FromValues[int](),
+		FromOptions(option.None[int](), option.None[int]()),
+	} {
+		assert.Assert(t, Fold(e, "", func(acc string, t int) string { return acc + strconv.Itoa(t) }).IsNone())
+		assert.Assert(t, Reduce(e, func(acc, t int) int { return acc + t }).IsNone())
+		assert.Assert(t, FirstSome(e).IsNone())
+		assert.Assert(t, LastSome(e).IsNone())
+		assert.Assert(t, MinFunc(e, cmp.Compare[int]).IsNone())
+		assert.Assert(t, MaxFunc(e, cmp.Compare[int]).IsNone())
+		assert.Assert(t, Sum(e).IsNone())
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	e := FromOptions(option.None[int](), option.Some(3), option.Some(1), option.None[int](), option.Some(5), option.None[int]())
+
+	assert.Assert(t, option.Equal(option.Some("-315"), Fold(e, "-", func(acc string, t int) string { return acc + strconv.Itoa(t) })))
+	assert.Assert(t, option.Equal(option.Some(15), Reduce(e, func(acc, t int) int { return acc * t })))
+	assert.Assert(t, option.Equal(option.Some(3), FirstSome(e)))
+	assert.Assert(t, option.Equal(option.Some(5), LastSome(e)))
+	assert.Assert(t, option.Equal(option.Some(1), MinFunc(e, cmp.Compare[int])))
+	assert.Assert(t, option.Equal(option.Some(5), MaxFunc(e, cmp.Compare[int])))
+	assert.Assert(t, option.Equal(option.Some(9), Sum(e)))
+	assert.Assert(t, option.Equal(option.Some(2.5), Sum(FromValues(1.5, 1.0))))
+
+	type pair struct {
+		k string
+		v int
+	}
+	pairs := FromValues(pair{"a", 1}, pair{"b", 2}, pair{"c", 1}, pair{"d", 2})
+	byV := func(a, b pair) int { return cmp.Compare(a.v, b.v) }
+	assert.Equal(t, "a", MinFunc(pairs, byV).Value().k)
+	assert.Equal(t, "b", MaxFunc(pairs, byV).Value().k)
+}
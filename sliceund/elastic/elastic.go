@@ -245,6 +245,13 @@ func (e *Elastic[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 		return err
 	}
 
+	e.append(t)
+	return nil
+}
+
+// append appends t to e.
+// If e is not defined or has no element, e will be replaced with a new Elastic[T].
+func (e *Elastic[T]) append(t option.Options[T]) {
 	if len(e.inner().Value()) == 0 {
 		*e = FromOptions(t...)
 	} else {
@@ -258,5 +265,4 @@ func (e *Elastic[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 			})
 		})
 	}
-	return nil
 }
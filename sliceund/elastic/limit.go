@@ -0,0 +1,28 @@
+package elastic
+
+import (
+	"github.com/ngicks/und/elastic"
+	"github.com/ngicks/und/undtag"
+)
+
+var (
+	// ErrTooManyElements is an alias for [elastic.ErrTooManyElements].
+	ErrTooManyElements = elastic.ErrTooManyElements
+	// ErrTooLarge is an alias for [elastic.ErrTooLarge].
+	ErrTooLarge = elastic.ErrTooLarge
+	// ErrNullElement is an alias for [elastic.ErrNullElement].
+	ErrNullElement = elastic.ErrNullElement
+)
+
+type (
+	// DecodeLimits is an alias for [elastic.DecodeLimits].
+	DecodeLimits = elastic.DecodeLimits
+	// LimitError is an alias for [elastic.LimitError].
+	LimitError = elastic.LimitError
+)
+
+// DecodeLimitsFromOption derives DecodeLimits from `und` struct tag options.
+// See [elastic.DecodeLimitsFromOption].
+func DecodeLimitsFromOption(opt undtag.UndOpt) DecodeLimits {
+	return elastic.DecodeLimitsFromOption(opt)
+}
@@ -0,0 +1,139 @@
+package elastic
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ngicks/und/undtag"
+	"gotest.tools/v3/assert"
+)
+
+// portable tests that can be copied from github.com/ngicks/und/elastic into github.com/ngicks/und/sliceund/elastic
+
+func TestElastic_UnmarshalJSONLimited(t *testing.T) {
+	type testCase struct {
+		input  string
+		limits DecodeLimits
+		err    error
+		index  int
+	}
+	for _, tc := range []testCase{
+		{`null`, DecodeLimits{NonNull: true, MaxElements: -1}, nil, 0},
+		{`"foo"`, DecodeLimits{MaxElements: 1}, nil, 0},
+		{`"foo"`, DecodeLimits{MaxElements: -1}, ErrTooManyElements, 0},
+		{`["foo","bar"]`, DecodeLimits{MaxElements: 2}, nil, 0},
+		{`["foo","bar","baz"]`, DecodeLimits{MaxElements: 2}, ErrTooManyElements, 2},
+		{`["foo","bar","baz"]`, DecodeLimits{MaxBytes: 10}, ErrTooLarge, -1},
+		{`["foo",null]`, DecodeLimits{NonNull: true}, ErrNullElement, 1},
+		{`["foo",null]`, DecodeLimits{}, nil, 0},
+	} {
+		var e Elastic[string]
+		err := e.UnmarshalJSONLimited([]byte(tc.input), tc.limits)
+		if tc.err == nil {
+			assert.NilError(t, err, "input = %s", tc.input)
+			continue
+		}
+		assert.ErrorIs(t, err, tc.err, "input = %s", tc.input)
+		var limitErr *LimitError
+		assert.Assert(t, errors.As(err, &limitErr))
+		assert.Equal(t, tc.index, limitErr.Index)
+	}
+
+	// An array is never decoded as a single value, which would bypass limits on elements.
+	var e Elastic[[]int]
+	err := e.UnmarshalJSONLimited([]byte(`[1,2,3]`), DecodeLimits{MaxElements: 1})
+	assert.Assert(t, err != nil)
+	var limitErr *LimitError
+	assert.Assert(t, !errors.As(err, &limitErr))
+	assert.Assert(t, e.IsUndefined())
+
+	assert.NilError(t, e.UnmarshalJSONLimited([]byte(`[[1,2,3]]`), DecodeLimits{MaxElements: 1}))
+	assert.Equal(t, 1, e.Len())
+	assert.DeepEqual(t, []int{1, 2, 3}, e.Value())
+}
+
+func TestElastic_UnmarshalXMLLimited(t *testing.T) {
+	input := `<sample><E>foo</E><E>bar</E><E>baz</E></sample>`
+
+	var (
+		e   Elastic[string]
+		err error
+	)
+	dec := xml.NewDecoder(strings.NewReader(input))
+	for err == nil {
+		var tok xml.Token
+		tok, err = dec.Token()
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "E" {
+			err = e.UnmarshalXMLLimited(dec, se, DecodeLimits{MaxElements: 2})
+		}
+	}
+	assert.ErrorIs(t, err, ErrTooManyElements)
+	assert.Assert(t, Equal(FromValues("foo", "bar"), e))
+
+	type sample struct {
+		E Elastic[string]
+	}
+	var s sample
+	assert.NilError(t, xml.Unmarshal([]byte(input), &s))
+	assert.Assert(t, Equal(FromValues("foo", "bar", "baz"), s.E))
+}
+
+func TestDecodeLimitsFromOption(t *testing.T) {
+	for tag, expected := range map[string]DecodeLimits{
+		"def":                   {},
+		"len>2":                 {},
+		"len==3":                {MaxElements: 3},
+		"len<=3,values:nonnull": {MaxElements: 3, NonNull: true},
+		"len<3":                 {MaxElements: 2},
+		"len<1":                 {MaxElements: -1},
+		"len=1..4":              {MaxElements: 4},
+		"len>=1,len<=5":         {MaxElements: 5},
+	} {
+		opt, err := undtag.ParseOption(tag)
+		assert.NilError(t, err)
+		assert.Equal(t, expected, DecodeLimitsFromOption(opt), "tag = %s", tag)
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
+}
+
+func TestElastic_UnmarshalXMLLimited_max_bytes(t *testing.T) {
+	input := `<E>` + strings.Repeat(`<x>a</x>`, 1<<17) + `</E>`
+	r := &countingReader{r: strings.NewReader(input)}
+	dec := xml.NewDecoder(r)
+	tok, err := dec.Token()
+	assert.NilError(t, err)
+
+	var e Elastic[string]
+	err = e.UnmarshalXMLLimited(dec, tok.(xml.StartElement), DecodeLimits{MaxBytes: 64})
+	assert.ErrorIs(t, err, ErrTooLarge)
+	// decoding stops before reading the entire input.
+	assert.Assert(t, r.n < len(input)/2, "read %d of %d bytes", r.n, len(input))
+
+	for _, input := range []string{`<E>foo</E>`, `<E><![CDATA[foo]]></E>`} {
+		dec = xml.NewDecoder(strings.NewReader(input))
+		tok, err = dec.Token()
+		assert.NilError(t, err)
+		e = Elastic[string]{}
+		assert.NilError(t, e.UnmarshalXMLLimited(dec, tok.(xml.StartElement), DecodeLimits{MaxBytes: 64}))
+		assert.Assert(t, Equal(FromValues("foo"), e))
+	}
+
+	dec = xml.NewDecoder(strings.NewReader(`<E>` + strings.Repeat("a", 65) + `</E>`))
+	tok, err = dec.Token()
+	assert.NilError(t, err)
+	err = e.UnmarshalXMLLimited(dec, tok.(xml.StartElement), DecodeLimits{MaxBytes: 64})
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
@@ -1,8 +1,11 @@
 package elastic
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
+	"io"
 	"iter"
 	"log/slog"
 	"slices"
@@ -118,6 +121,158 @@ func (e *Elastic[T]) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// UnmarshalJSONLimited is like [Elastic.UnmarshalJSON] but enforces limits while decoding.
+// Array elements are decoded one by one and the decoding stops at the first element violating limits.
+//
+// Unlike UnmarshalJSON, a JSON array is always decoded as elements.
+// If T is a slice or an array, a single T encoded as a JSON array is rejected,
+// since decoding it as a single value would bypass the limits on elements.
+//
+// UnmarshalJSONLimited returns *LimitError if data violates limits.
+func (e *Elastic[T]) UnmarshalJSONLimited(data []byte, limits DecodeLimits) error {
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return &LimitError{Err: ErrTooLarge, Limit: limits.MaxBytes, Index: -1}
+	}
+
+	if string(data) == "null" {
+		*e = Null[T]()
+		return nil
+	}
+
+	if len(data) >= 2 && data[0] == '[' {
+		t, err := decodeOptionsLimited[T](data, limits)
+		if err != nil {
+			return err
+		}
+		*e = FromOptions(t...)
+		return nil
+	}
+
+	if err := checkLenLimit(limits, 0); err != nil {
+		return err
+	}
+	var t option.Option[T]
+	err := json.Unmarshal(data, &t)
+	if err != nil {
+		return err
+	}
+	if limits.NonNull && t.IsNone() {
+		return &LimitError{Err: ErrNullElement, Index: 0}
+	}
+	*e = FromOptions(t)
+	return nil
+}
+
+func decodeOptionsLimited[T any](data []byte, limits DecodeLimits) (option.Options[T], error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil { // consumes '['
+		return nil, err
+	}
+	opts := make(option.Options[T], 0)
+	for dec.More() {
+		if err := checkLenLimit(limits, len(opts)); err != nil {
+			return nil, err
+		}
+		var opt option.Option[T]
+		if err := dec.Decode(&opt); err != nil {
+			return nil, err
+		}
+		if limits.NonNull && opt.IsNone() {
+			return nil, &LimitError{Err: ErrNullElement, Index: len(opts)}
+		}
+		opts = append(opts, opt)
+	}
+	if _, err := dec.Token(); err != nil { // consumes ']'
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("elastic: invalid data after top-level value")
+	}
+	return opts, nil
+}
+
+// checkLenLimit returns an error if an element can not be added to n elements.
+func checkLenLimit(limits DecodeLimits, n int) error {
+	if limits.MaxElements == 0 || n < limits.MaxElements {
+		return nil
+	}
+	return &LimitError{Err: ErrTooManyElements, Limit: int64(max(limits.MaxElements, 0)), Index: n}
+}
+
+// UnmarshalXMLLimited is like [Elastic.UnmarshalXML] but enforces limits while decoding.
+//
+// Since repeated elements are appended to e, MaxElements limits the total length of e.
+// MaxBytes limits size of each element. If MaxBytes is set, tokens of the element are read one by one
+// and the decoding stops as soon as the element exceeds MaxBytes.
+//
+// UnmarshalXMLLimited returns *LimitError if the input violates limits.
+func (e *Elastic[T]) UnmarshalXMLLimited(d *xml.Decoder, start xml.StartElement, limits DecodeLimits) error {
+	if err := checkLenLimit(limits, e.Len()); err != nil {
+		return err
+	}
+
+	var t option.Options[T]
+	if limits.MaxBytes > 0 {
+		toks, err := readElementLimited(d, start, limits.MaxBytes, e.Len())
+		if err != nil {
+			return err
+		}
+		if err := xml.NewTokenDecoder(&toks).Decode(&t); err != nil {
+			return err
+		}
+	} else if err := d.DecodeElement(&t, &start); err != nil {
+		return err
+	}
+	for i, opt := range t {
+		if err := checkLenLimit(limits, e.Len()+i); err != nil {
+			return err
+		}
+		if limits.NonNull && opt.IsNone() {
+			return &LimitError{Err: ErrNullElement, Index: e.Len() + i}
+		}
+	}
+
+	e.append(t)
+	return nil
+}
+
+// readElementLimited reads tokens from d up to the end element matching start, which is already read from d.
+// It returns *LimitError as soon as the element exceeds maxBytes.
+// Returned tokens start with start.
+func readElementLimited(d *xml.Decoder, start xml.StartElement, maxBytes int64, index int) (tokenSlice, error) {
+	offset := d.InputOffset()
+	toks := tokenSlice{start.Copy()}
+	for depth := 1; depth > 0; {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if d.InputOffset()-offset > maxBytes {
+			return nil, &LimitError{Err: ErrTooLarge, Limit: maxBytes, Index: index}
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		toks = append(toks, xml.CopyToken(tok))
+	}
+	return toks, nil
+}
+
+// tokenSlice is a xml.TokenReader reading tokens from the slice.
+type tokenSlice []xml.Token
+
+func (s *tokenSlice) Token() (xml.Token, error) {
+	if len(*s) == 0 {
+		return nil, io.EOF
+	}
+	tok := (*s)[0]
+	*s = (*s)[1:]
+	return tok, nil
+}
+
 // MarshalXML implements xml.Marshaler.
 func (e Elastic[T]) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
 	return e.Unwrap().MarshalXML(enc, start)
@@ -0,0 +1,6 @@
+package elastic
+
+import "github.com/ngicks/und/elastic"
+
+// Numeric is an alias for [elastic.Numeric].
+type Numeric = elastic.Numeric
@@ -0,0 +1,16 @@
+package elastic
+
+import (
+	"encoding/json"
+	"iter"
+
+	"github.com/ngicks/und/elastic"
+	"github.com/ngicks/und/option"
+)
+
+// DecodeJSONSeq reads an Elastic[T] value from dec and returns an iterator over its elements
+// without materializing the whole value.
+// See [elastic.DecodeJSONSeq].
+func DecodeJSONSeq[T any](dec *json.Decoder) (seq iter.Seq2[option.Option[T], error], null bool, err error) {
+	return elastic.DecodeJSONSeq[T](dec)
+}
@@ -37,6 +37,9 @@ func Defined[T any](t T) Und[T] {
 }
 
 // Null returns a null Und[T].
+//
+// Each call returns a new value not sharing its backing array with others,
+// so that writing to one never changes another.
 func Null[T any]() Und[T] {
 	return Und[T]{option.None[T]()}
 }
@@ -70,12 +73,16 @@ func WrapPointer[T any](t *T) Und[*T] {
 }
 
 // FromOptions converts opt into an Und[T].
-// opt is retained by the returned value.
+// The value of opt is copied into a newly allocated backing array.
 func FromOption[T any](opt option.Option[option.Option[T]]) Und[T] {
-	if opt.IsNone() {
+	switch {
+	case opt.IsNone():
 		return Undefined[T]()
+	case opt.Value().IsNone():
+		return Null[T]()
+	default:
+		return Defined(opt.Value().Value())
 	}
-	return Und[T]{opt.Value()}
 }
 
 // FromUnd converts non-slice version of Und[T] into Und[T].
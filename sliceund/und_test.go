@@ -249,3 +249,22 @@ func Test_Clone(t *testing.T) {
 	cloned = Clone(undefined)
 	assert.Assert(t, cloned.IsUndefined())
 }
+
+func TestUnd_null_not_shared(t *testing.T) {
+	u := Null[int]()
+	assert.NilError(t, u.UnmarshalJSON([]byte(`15`)))
+	assert.Equal(t, 15, u.Value())
+	assert.Assert(t, Null[int]().IsNull())
+
+	// writing through the element of a null Und must not affect others.
+	u = Null[int]()
+	u[0] = option.Some(15)
+	assert.Assert(t, u.IsDefined())
+	assert.Assert(t, Null[int]().IsNull())
+
+	// UnmarshalJSON reuses the backing array of a non-undefined Und.
+	u = Defined(1)
+	null := []byte(`null`)
+	assert.Equal(t, float64(0), testing.AllocsPerRun(10, func() { _ = u.UnmarshalJSON(null) }))
+	assert.Assert(t, u.IsNull())
+}
@@ -32,20 +32,34 @@ var (
 // if `json:",omitzero"` option is attached to those fields.
 // For Go 1.23 or older version, instead you can use the sliceund variant with `json:",omitempty"` option.
 type Und[T any] struct {
-	opt option.Option[option.Option[T]]
+	// state is kept next to v instead of nesting options
+	// so that Und[T] only costs a single byte (plus padding) over T.
+	state undState
+	v     T
 }
 
+// undState is the internal state of Und[T].
+// The zero value must be undefined so that the zero Und[T] is undefined.
+type undState uint8
+
+const (
+	undStateUndefined undState = iota
+	undStateNull
+	undStateDefined
+)
+
 // Defined returns a defined Und[T] whose internal value is t.
 func Defined[T any](t T) Und[T] {
 	return Und[T]{
-		opt: option.Some(option.Some(t)),
+		state: undStateDefined,
+		v:     t,
 	}
 }
 
 // Null returns a null Und[T].
 func Null[T any]() Und[T] {
 	return Und[T]{
-		opt: option.Some(option.None[T]()),
+		state: undStateNull,
 	}
 }
 
@@ -78,9 +92,16 @@ func WrapPointer[T any](t *T) Und[*T] {
 }
 
 // FromOptions converts opt into an Und[T].
-// opt is retained by the returned value.
+// The value of opt is copied into the returned value.
 func FromOption[T any](opt option.Option[option.Option[T]]) Und[T] {
-	return Und[T]{opt: opt}
+	switch {
+	case opt.IsNone():
+		return Undefined[T]()
+	case opt.Value().IsNone():
+		return Null[T]()
+	default:
+		return Defined(opt.Value().Value())
+	}
 }
 
 // FromSqlNull converts a valid sql.Null[T] to a defined Und[T]
@@ -99,17 +120,17 @@ func (u Und[T]) IsZero() bool {
 
 // IsDefined returns true if u is a defined value, otherwise false.
 func (u Und[T]) IsDefined() bool {
-	return u.opt.IsSome() && u.opt.Value().IsSome()
+	return u.state == undStateDefined
 }
 
 // IsNull returns true if u is a null value, otherwise false.
 func (u Und[T]) IsNull() bool {
-	return u.opt.IsSome() && u.opt.Value().IsNone()
+	return u.state == undStateNull
 }
 
 // IsUndefined returns true if u is an undefined value, otherwise false.
 func (u Und[T]) IsUndefined() bool {
-	return u.opt.IsNone()
+	return u.state == undStateUndefined
 }
 
 // EqualFunc reports whether two Und values are equal.
@@ -120,12 +141,13 @@ func (u Und[T]) IsUndefined() bool {
 // If T is just a comparable type, use [Equal].
 // If T is an implementor of interface { Equal(t T) bool }, e.g time.Time, use [EqualEqualer].
 func (u Und[T]) EqualFunc(t Und[T], cmp func(i, j T) bool) bool {
-	return u.opt.EqualFunc(
-		t.opt,
-		func(i, j option.Option[T]) bool {
-			return i.EqualFunc(j, cmp)
-		},
-	)
+	if u.state != t.state {
+		return false
+	}
+	if u.state != undStateDefined {
+		return true
+	}
+	return cmp(u.v, t.v)
 }
 
 // Equal tests equality of l and r then returns true if they are equal, false otherwise.
@@ -146,11 +168,10 @@ func EqualEqualer[T interface{ Equal(t T) bool }](l, r Und[T]) bool {
 
 // CloneFunc clones u using the cloneT functions.
 func (u Und[T]) CloneFunc(cloneT func(T) T) Und[T] {
-	return u.InnerMap(func(o option.Option[option.Option[T]]) option.Option[option.Option[T]] {
-		return o.CloneFunc(func(o option.Option[T]) option.Option[T] {
-			return o.CloneFunc(cloneT)
-		})
-	})
+	if !u.IsDefined() {
+		return u
+	}
+	return Defined(cloneT(u.v))
 }
 
 // Clone clones u.
@@ -161,20 +182,17 @@ func Clone[T comparable](u Und[T]) Und[T] {
 }
 
 func (u Und[T]) UndValidate() error {
-	return u.opt.Value().UndValidate()
+	return u.inner().UndValidate()
 }
 
 func (u Und[T]) UndCheck() error {
-	return u.opt.UndCheck()
+	return u.Unwrap().UndCheck()
 }
 
 // Value returns an internal value.
 func (u Und[T]) Value() T {
-	if u.IsDefined() {
-		return u.opt.Value().Value()
-	}
-	var zero T
-	return zero
+	// v is always zero unless u is defined.
+	return u.v
 }
 
 // Pointer returns u's internal value as a pointer.
@@ -183,7 +201,8 @@ func (u Und[T]) Pointer() *T {
 	if !u.IsDefined() {
 		return nil
 	}
-	return u.opt.Value().Pointer()
+	t := u.v
+	return &t
 }
 
 // DoublePointer returns nil if u is undefined, &(*T)(nil) if null, the internal value if defined.
@@ -195,7 +214,7 @@ func (u Und[T]) DoublePointer() **T {
 		var t *T
 		return &t
 	default:
-		t := u.opt.Value().Value()
+		t := u.v
 		tt := &t
 		return &tt
 	}
@@ -203,7 +222,18 @@ func (u Und[T]) DoublePointer() **T {
 
 // Unwrap returns u's internal value.
 func (u Und[T]) Unwrap() option.Option[option.Option[T]] {
-	return u.opt
+	if u.IsUndefined() {
+		return option.None[option.Option[T]]()
+	}
+	return option.Some(u.inner())
+}
+
+// inner returns u as a single option, treating undefined as none.
+func (u Und[T]) inner() option.Option[T] {
+	if !u.IsDefined() {
+		return option.None[T]()
+	}
+	return option.Some(u.v)
 }
 
 // Deprecated: Renamed to [Und.InnerMap]. Und.Map has same name but behavior is inconsistent to [Map].
@@ -214,7 +244,7 @@ func (u Und[T]) Map(f func(option.Option[option.Option[T]]) option.Option[option
 // InnerMap returns a new Und[T] whose internal value is u's mapped by f.
 // Unlike [Map], f is invoked even when u is not an undined value.
 func (u Und[T]) InnerMap(f func(option.Option[option.Option[T]]) option.Option[option.Option[T]]) Und[T] {
-	return Und[T]{opt: f(u.opt)}
+	return FromOption(f(u.Unwrap()))
 }
 
 // MarshalJSON implements json.Marshaler.
@@ -222,7 +252,7 @@ func (u Und[T]) MarshalJSON() ([]byte, error) {
 	if !u.IsDefined() {
 		return []byte(`null`), nil
 	}
-	return json.Marshal(u.opt.Value().Value())
+	return json.Marshal(u.v)
 }
 
 // UnmarshalJSON implements json.Unmarshaler.
@@ -244,7 +274,7 @@ func (u *Und[T]) UnmarshalJSON(data []byte) error {
 
 // MarshalXML implements xml.Marshaler.
 func (o Und[T]) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
-	return o.opt.Value().MarshalXML(e, start)
+	return o.inner().MarshalXML(e, start)
 }
 
 // UnmarshalXML implements xml.Unmarshaler.
@@ -262,12 +292,12 @@ func (o *Und[T]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 
 // LogValue implements slog.LogValuer.
 func (u Und[T]) LogValue() slog.Value {
-	return u.opt.Value().LogValue()
+	return u.inner().LogValue()
 }
 
 // SqlNull converts o into sql.Null[T].
 func (u Und[T]) SqlNull() sql.Null[T] {
-	return u.opt.Value().SqlNull()
+	return u.inner().SqlNull()
 }
 
 // State returns u's value state.
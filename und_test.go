@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/ngicks/und"
 	"github.com/ngicks/und/internal/testcase"
@@ -247,3 +248,11 @@ func Test_Clone(t *testing.T) {
 	cloned = und.Clone(undefined)
 	assert.Assert(t, cloned.IsUndefined())
 }
+
+func TestUnd_size(t *testing.T) {
+	// state must be packed next to T, not nested as 2 options.
+	assert.Equal(t, unsafe.Sizeof(struct {
+		b bool
+		v int64
+	}{}), unsafe.Sizeof(und.Und[int64]{}))
+}
@@ -0,0 +1,423 @@
+package undtag
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ngicks/und/internal/option"
+)
+
+// ErrIncompatibleOption is returned by [UndOpt.Intersect] if no value could satisfy both options,
+// or they have a same named option with different arguments.
+var ErrIncompatibleOption = errors.New("incompatible option")
+
+// Builder builds UndOpt programmatically.
+//
+// Methods can be chained and options are accumulated as if they are written in `und` struct tag.
+// Errors, including conflicts between options, are reported by Build.
+//
+//	opt, err := undtag.NewBuilder().Def().Null().LenRange(1, 5).Build()
+type Builder struct {
+	states   []string
+	len      []string
+	values   []string
+	groups   []string
+	requires []string
+	custom   []string
+	err      error
+}
+
+// NewBuilder returns a new Builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Required adds required option.
+func (b *Builder) Required() *Builder { return b.state(UndTagValueRequired) }
+
+// Nullish adds nullish option.
+func (b *Builder) Nullish() *Builder { return b.state(UndTagValueNullish) }
+
+// Def adds def option.
+func (b *Builder) Def() *Builder { return b.state(UndTagValueDef) }
+
+// Null adds null option.
+func (b *Builder) Null() *Builder { return b.state(UndTagValueNull) }
+
+// Und adds und option.
+func (b *Builder) Und() *Builder { return b.state(UndTagValueUnd) }
+
+func (b *Builder) state(s string) *Builder {
+	b.states = append(b.states, s)
+	return b
+}
+
+// Len adds len option, e.g. Len(LenOpGrEq, 1) for len>=1.
+// A lower bound and an upper bound are merged into a range, as `und:"len>=1,len<=5"` is.
+func (b *Builder) Len(op LenOp, n int) *Builder {
+	if op < LenOpEqEq || op >= LenOpRange || n < 0 {
+		b.setErr(fmt.Errorf("%w: op %s, len %d", ErrMalformedLen, op, n))
+		return b
+	}
+	b.len = append(b.len, LenValidator{Len: n, Op: op}.String())
+	return b
+}
+
+// LenRange adds len option formatted as len=min..max.
+func (b *Builder) LenRange(min, max int) *Builder {
+	if min < 0 || max < 0 {
+		b.setErr(fmt.Errorf("%w: range %d..%d", ErrMalformedLen, min, max))
+		return b
+	}
+	b.len = append(b.len, LenValidator{Len: min, Op: LenOpRange, Max: max}.String())
+	return b
+}
+
+// Values adds values options set in v.
+func (b *Builder) Values(v ValuesValidator) *Builder {
+	b.values = append(b.values, v.options()...)
+	return b
+}
+
+// Group adds group option.
+func (b *Builder) Group(name string, kind GroupKind) *Builder {
+	b.groups = append(b.groups, GroupOption{Name: name, Kind: kind}.String())
+	return b
+}
+
+// Requires adds requires option.
+func (b *Builder) Requires(field string) *Builder {
+	b.requires = append(b.requires, UndTagValueRequires+"="+field)
+	return b
+}
+
+// Custom adds a constraint registered by [RegisterConstraint]. arg may be empty.
+func (b *Builder) Custom(name, arg string) *Builder {
+	b.custom = append(b.custom, CustomOption{Name: name, Arg: arg}.String())
+	return b
+}
+
+func (b *Builder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Build returns built UndOpt.
+// It returns an error if options conflict, or ones added to b are malformed.
+func (b *Builder) Build() (UndOpt, error) {
+	if b.err != nil {
+		return UndOpt{}, b.err
+	}
+	var opts []string
+	for _, o := range [][]string{b.states, b.len, b.values, b.groups, b.requires, b.custom} {
+		for _, opt := range o {
+			if strings.ContainsAny(opt, ",;") {
+				return UndOpt{}, fmt.Errorf("%w: option must not contain \",\" nor \";\": %q", ErrUnknownOption, opt)
+			}
+			opts = append(opts, opt)
+		}
+	}
+	return ParseOption(strings.Join(opts, ","))
+}
+
+// String returns g formatted as group=name:kind.
+func (g GroupOption) String() string {
+	return UndTagValueGroup + "=" + g.Name + ":" + g.Kind.String()
+}
+
+// String returns c formatted as name or name=arg.
+func (c CustomOption) String() string {
+	if c.Arg == "" {
+		return c.Name
+	}
+	return c.Name + "=" + c.Arg
+}
+
+// String returns s formatted as options, e.g. required or def,null.
+// Options are ordered as def, null and und.
+func (s StateValidator) String() string {
+	if s.filled {
+		if s.Def {
+			return UndTagValueRequired
+		}
+		return UndTagValueNullish
+	}
+	var opts []string
+	if s.Def {
+		opts = append(opts, UndTagValueDef)
+	}
+	if s.Null {
+		opts = append(opts, UndTagValueNull)
+	}
+	if s.Und {
+		opts = append(opts, UndTagValueUnd)
+	}
+	return strings.Join(opts, ",")
+}
+
+// Filled reports whether s is specified by required or nullish.
+func (s StateValidator) Filled() bool {
+	return s.filled
+}
+
+func (v ValuesValidator) options() []string {
+	var opts []string
+	if v.Nonnull {
+		opts = append(opts, UndTagValueValues+":nonnull")
+	}
+	if v.Unique {
+		opts = append(opts, UndTagValueValues+":unique")
+	}
+	if v.Nonzero {
+		opts = append(opts, UndTagValueValues+":nonzero")
+	}
+	if v.Sorted {
+		opts = append(opts, UndTagValueValues+":sorted")
+	}
+	return opts
+}
+
+// String returns v formatted as values options, e.g. values:nonnull,values:unique.
+func (v ValuesValidator) String() string {
+	return strings.Join(v.options(), ",")
+}
+
+// String returns the canonical form of o as the value of `und` struct tag.
+// The result is parsed by [ParseOption] into an UndOpt equivalent to o.
+//
+// Options are ordered as states, len, values, group, requires and registered constraints.
+// Presets are not restored; they are expanded when parsed.
+func (o UndOpt) String() string {
+	var opts []string
+	if s, ok := o.states.Get(); ok {
+		if ss := s.String(); ss != "" {
+			opts = append(opts, ss)
+		}
+	}
+	if l, ok := o.len.Get(); ok {
+		opts = append(opts, l.String())
+	}
+	if v, ok := o.values.Get(); ok {
+		opts = append(opts, v.options()...)
+	}
+	for _, g := range o.groups {
+		opts = append(opts, g.String())
+	}
+	for _, r := range o.requires {
+		opts = append(opts, UndTagValueRequires+"="+r)
+	}
+	for _, c := range o.custom {
+		opts = append(opts, c.String())
+	}
+	return strings.Join(opts, ",")
+}
+
+// LookupStates returns the state options of o. ok is false if o has none of state options.
+func (o UndOpt) LookupStates() (s StateValidator, ok bool) {
+	return o.states.Get()
+}
+
+// LookupLen returns the len option of o. ok is false if o has no len option.
+func (o UndOpt) LookupLen() (l LenValidator, ok bool) {
+	return o.len.Get()
+}
+
+// LookupValues returns the values options of o. ok is false if o has no values option.
+func (o UndOpt) LookupValues() (v ValuesValidator, ok bool) {
+	return o.values.Get()
+}
+
+// Export converts o into UndOptExport.
+func (o UndOpt) Export() UndOptExport {
+	return UndOptExport{
+		States:   o.states.Pointer(),
+		Len:      o.len.Pointer(),
+		Values:   o.values.Pointer(),
+		Custom:   slices.Clone(o.custom),
+		Groups:   slices.Clone(o.groups),
+		Requires: slices.Clone(o.requires),
+	}
+}
+
+// Intersect returns an UndOpt which only allows values allowed by both o and other.
+//
+// States and len are narrowed, values options and other options are combined.
+// It returns an error wrapping [ErrIncompatibleOption] if no value could satisfy both,
+// e.g. def and null, or len<=1 and len>=2,
+// or o and other have a same group or a same registered constraint with different arguments.
+func (o UndOpt) Intersect(other UndOpt) (UndOpt, error) {
+	var result UndOpt
+
+	switch s, t := o.states, other.states; {
+	case s.IsSome() && t.IsSome():
+		a, b := s.Value(), t.Value()
+		merged := StateValidator{Def: a.Def && b.Def, Null: a.Null && b.Null, Und: a.Und && b.Und}
+		if !merged.Def && !merged.Null && !merged.Und {
+			return UndOpt{}, fmt.Errorf("%w: states %s and %s", ErrIncompatibleOption, a, b)
+		}
+		merged.filled = a.filled && merged == a.withoutFilled() || b.filled && merged == b.withoutFilled()
+		result.states = option.Some(merged)
+	default:
+		result.states = s.Or(t)
+	}
+
+	switch l, m := o.len, other.len; {
+	case l.IsSome() && m.IsSome():
+		a, b := l.Value(), m.Value()
+		aMin, aMax, aHasMin, aHasMax, aOk := a.bounds()
+		bMin, bMax, bHasMin, bHasMax, bOk := b.bounds()
+		if !aOk || !bOk {
+			return UndOpt{}, fmt.Errorf("%w: %s and %s", ErrIncompatibleOption, a, b)
+		}
+		lo, hi := max(aMin, bMin), 0
+		switch {
+		case aHasMax && bHasMax:
+			hi = min(aMax, bMax)
+		case aHasMax:
+			hi = aMax
+		case bHasMax:
+			hi = bMax
+		}
+		merged, ok := lenFromBounds(lo, hi, aHasMin || bHasMin, aHasMax || bHasMax)
+		if !ok {
+			return UndOpt{}, fmt.Errorf("%w: %s and %s", ErrIncompatibleOption, a, b)
+		}
+		result.len = merged
+	default:
+		result.len = l.Or(m)
+	}
+
+	switch v, w := o.values, other.values; {
+	case v.IsSome() && w.IsSome():
+		a, b := v.Value(), w.Value()
+		result.values = option.Some(ValuesValidator{
+			Nonnull: a.Nonnull || b.Nonnull,
+			Unique:  a.Unique || b.Unique,
+			Nonzero: a.Nonzero || b.Nonzero,
+			Sorted:  a.Sorted || b.Sorted,
+		})
+	default:
+		result.values = v.Or(w)
+	}
+
+	result.groups = slices.Clone(o.groups)
+	for _, g := range other.groups {
+		i := slices.IndexFunc(result.groups, func(gg GroupOption) bool { return gg.Name == g.Name })
+		switch {
+		case i < 0:
+			result.groups = append(result.groups, g)
+		case result.groups[i].Kind != g.Kind:
+			return UndOpt{}, fmt.Errorf("%w: %s and %s", ErrIncompatibleOption, result.groups[i], g)
+		}
+	}
+
+	result.requires = slices.Clone(o.requires)
+	for _, r := range other.requires {
+		if !slices.Contains(result.requires, r) {
+			result.requires = append(result.requires, r)
+		}
+	}
+
+	result.custom = slices.Clone(o.custom)
+	for _, c := range other.custom {
+		i := slices.IndexFunc(result.custom, func(cc CustomOption) bool { return cc.Name == c.Name })
+		switch {
+		case i < 0:
+			result.custom = append(result.custom, c)
+		case result.custom[i].Arg != c.Arg:
+			return UndOpt{}, fmt.Errorf("%w: %s and %s", ErrIncompatibleOption, result.custom[i], c)
+		}
+	}
+
+	return result, nil
+}
+
+// Union returns an UndOpt which allows any value allowed by either o or other.
+//
+// Since a union of constraints is not always expressible as options,
+// the result may allow values neither of o nor other allow, e.g. the union of len==1 and len==3 is len=1..3.
+// States and len are widened, and other options are kept only if both have them.
+// An option which only one of o and other has is dropped.
+func (o UndOpt) Union(other UndOpt) UndOpt {
+	var result UndOpt
+
+	if s, t := o.states, other.states; s.IsSome() && t.IsSome() {
+		a, b := s.Value(), t.Value()
+		merged := StateValidator{Def: a.Def || b.Def, Null: a.Null || b.Null, Und: a.Und || b.Und}
+		merged.filled = a.filled && merged == a.withoutFilled() || b.filled && merged == b.withoutFilled()
+		result.states = option.Some(merged)
+	}
+
+	if l, m := o.len, other.len; l.IsSome() && m.IsSome() {
+		aMin, aMax, aHasMin, aHasMax, aOk := l.Value().bounds()
+		bMin, bMax, bHasMin, bHasMax, bOk := m.Value().bounds()
+		switch {
+		case !aOk:
+			result.len = m
+		case !bOk:
+			result.len = l
+		default:
+			// a side is bounded only if both bound it.
+			result.len, _ = lenFromBounds(min(aMin, bMin), max(aMax, bMax), aHasMin && bHasMin, aHasMax && bHasMax)
+		}
+	}
+
+	if v, w := o.values, other.values; v.IsSome() && w.IsSome() {
+		a, b := v.Value(), w.Value()
+		merged := ValuesValidator{
+			Nonnull: a.Nonnull && b.Nonnull,
+			Unique:  a.Unique && b.Unique,
+			Nonzero: a.Nonzero && b.Nonzero,
+			Sorted:  a.Sorted && b.Sorted,
+		}
+		if merged != (ValuesValidator{}) {
+			result.values = option.Some(merged)
+		}
+	}
+
+	for _, g := range o.groups {
+		if slices.Contains(other.groups, g) {
+			result.groups = append(result.groups, g)
+		}
+	}
+	for _, r := range o.requires {
+		if slices.Contains(other.requires, r) {
+			result.requires = append(result.requires, r)
+		}
+	}
+	for _, c := range o.custom {
+		if slices.ContainsFunc(other.custom, func(cc CustomOption) bool { return cc.Name == c.Name && cc.Arg == c.Arg }) {
+			result.custom = append(result.custom, c)
+		}
+	}
+
+	return result
+}
+
+func (s StateValidator) withoutFilled() StateValidator {
+	s.filled = false
+	return s
+}
+
+// lenFromBounds converts inclusive bounds lo and hi into LenValidator.
+// It returns None if neither side is bounded. ok is false if lo is greater than hi.
+func lenFromBounds(lo, hi int, hasMin, hasMax bool) (l option.Option[LenValidator], ok bool) {
+	switch {
+	case hasMin && hasMax:
+		if lo > hi {
+			return option.None[LenValidator](), false
+		}
+		if lo == hi {
+			return option.Some(LenValidator{Len: lo, Op: LenOpEqEq}), true
+		}
+		return option.Some(LenValidator{Len: lo, Op: LenOpRange, Max: hi}), true
+	case hasMin:
+		return option.Some(LenValidator{Len: lo, Op: LenOpGrEq}), true
+	case hasMax:
+		return option.Some(LenValidator{Len: hi, Op: LenOpLeEq}), true
+	}
+	return option.None[LenValidator](), true
+}
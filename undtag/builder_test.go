@@ -0,0 +1,165 @@
+package undtag_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ngicks/und/undtag"
+	"gotest.tools/v3/assert"
+)
+
+func TestUndOpt_String(t *testing.T) {
+	for _, tag := range []string{
+		"required",
+		"nullish",
+		"def,null,und",
+		"null,und",
+		"def,len>=1",
+		"required,len=1..5",
+		"def,und,len<3,values:nonnull,values:sorted",
+		"values:unique",
+		"def,und,group=payment:exactly-one,requires=City",
+	} {
+		opt, err := undtag.ParseOption(tag)
+		assert.NilError(t, err)
+		assert.Equal(t, tag, opt.String())
+		reparsed, err := undtag.ParseOption(opt.String())
+		assert.NilError(t, err)
+		assert.Assert(t, reflect.DeepEqual(opt.Export(), reparsed.Export()))
+	}
+
+	for tag, canonical := range map[string]string{
+		"und,def":                      "def,und",
+		"len>2":                        "def,len>2",
+		"len<=5,len>=1":                "def,len=1..5",
+		"values:sorted,values:nonnull": "values:nonnull,values:sorted",
+	} {
+		opt, err := undtag.ParseOption(tag)
+		assert.NilError(t, err)
+		assert.Equal(t, canonical, opt.String(), "tag = %s", tag)
+	}
+}
+
+func TestUndOpt_String_round_trip(t *testing.T) {
+	// tags in tests of this module which are valid without registered constraints.
+	for _, tag := range []string{
+		"def", "null", "und", "def,null", "def,und", "null,und", "def,null,und", "required", "nullish",
+		"def,len<=2", "def,len==2", "def,len>=1", "def,und,len=1..3", "def,und,len>0,len<3",
+		"def,und,len>=1,len<=2,values:sorted", "def,und,values:nonnull", "def,und,values:unique",
+		"def,null,und,group=payment:exactly-one", "def,und,group=payment:exactly-one",
+		"group=address:all-or-none", "group=contact:at-least-one", "group=g:at-least-one", "group=g:exactly-one",
+		"group=payment:exactly-one,group=contact:at-least-one",
+		"requires=C", "requires=City,group=address:all-or-none", "requires=city",
+		"len<1", "len<=1", "len<=3", "len==1", "len>1", "len>=1", "len=1..3,values:nonnull",
+		"null,len==2", "null,len==3", "und,len==1", "und,len==2", "und,len>=1",
+		"nullish,len==2", "nullish,len==0", "len==0,nullish", "required,len==2", "required,len>=2",
+		"values:nonnull", "values:nonnull,len==3", "values:nonnull,values:unique", "values:nonzero",
+		"values:sorted", "values:sorted,values:unique", "values:unique",
+	} {
+		opt, err := undtag.ParseOption(tag)
+		assert.NilError(t, err, "tag = %s", tag)
+		reparsed, err := undtag.ParseOption(opt.String())
+		assert.NilError(t, err, "tag = %s, String() = %s", tag, opt.String())
+		assert.Assert(
+			t,
+			reflect.DeepEqual(opt.Export(), reparsed.Export()),
+			"tag = %s, String() = %s: %#v != %#v", tag, opt.String(), opt.Export(), reparsed.Export(),
+		)
+		assert.Equal(t, opt.String(), reparsed.String())
+		assert.Equal(t, opt.Describe(), reparsed.Describe())
+	}
+
+	for tag, canonical := range map[string]string{
+		"nullish,len==2":  "def,null,und,len==2",
+		"len==0,nullish":  "def,null,und,len==0",
+		"required,len>=2": "required,len>=2",
+	} {
+		opt, err := undtag.ParseOption(tag)
+		assert.NilError(t, err)
+		assert.Equal(t, canonical, opt.String(), "tag = %s", tag)
+		assert.Assert(t, !opt.States().Value().Filled() || tag == "required,len>=2")
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	opt, err := undtag.NewBuilder().
+		Def().
+		Und().
+		Len(undtag.LenOpGrEq, 1).
+		Len(undtag.LenOpLe, 4).
+		Values(undtag.ValuesValidator{Nonnull: true}).
+		Group("payment", undtag.GroupAtMostOne).
+		Requires("City").
+		Build()
+	assert.NilError(t, err)
+	assert.Equal(t, "def,und,len=1..3,values:nonnull,group=payment:at-most-one,requires=City", opt.String())
+
+	states, ok := opt.LookupStates()
+	assert.Assert(t, ok)
+	assert.Equal(t, undtag.StateValidator{Def: true, Und: true}, states)
+	l, ok := opt.LookupLen()
+	assert.Assert(t, ok)
+	assert.Equal(t, undtag.LenValidator{Len: 1, Op: undtag.LenOpRange, Max: 3}, l)
+	_, ok = undtag.UndOpt{}.LookupValues()
+	assert.Assert(t, !ok)
+
+	opt, err = undtag.NewBuilder().LenRange(2, 2).Required().Build()
+	assert.NilError(t, err)
+	assert.Equal(t, "required,len=2..2", opt.String())
+
+	_, err = undtag.NewBuilder().Required().Def().Build()
+	assert.ErrorIs(t, err, undtag.ErrMultipleOption)
+	_, err = undtag.NewBuilder().Len(undtag.LenOpRange, 1).Build()
+	assert.ErrorIs(t, err, undtag.ErrMalformedLen)
+	_, err = undtag.NewBuilder().LenRange(3, 1).Build()
+	assert.ErrorIs(t, err, undtag.ErrMalformedLen)
+	_, err = undtag.NewBuilder().Custom("not-registered", "").Build()
+	assert.ErrorIs(t, err, undtag.ErrUnknownOption)
+	_, err = undtag.NewBuilder().Requires("a,b").Build()
+	assert.ErrorIs(t, err, undtag.ErrUnknownOption)
+
+	assert.Assert(t, reflect.DeepEqual(opt.Export(), opt.Export().Into().Export()))
+
+	// operators can be held in variables of the exported type, e.g. by code generators.
+	b := undtag.NewBuilder()
+	for op, n := range map[undtag.LenOp]int{undtag.LenOpGr: 1, undtag.LenOpLeEq: 3} {
+		b.Len(op, n)
+	}
+	opt, err = b.Build()
+	assert.NilError(t, err)
+	assert.Equal(t, "def,len=2..3", opt.String())
+}
+
+func TestUndOpt_Intersect_Union(t *testing.T) {
+	parse := func(tag string) undtag.UndOpt {
+		t.Helper()
+		opt, err := undtag.ParseOption(tag)
+		assert.NilError(t, err)
+		return opt
+	}
+
+	for _, tc := range []struct {
+		a, b, intersect, union string
+	}{
+		{"def,null,und", "def,und", "def,und", "def,null,und"},
+		{"required", "def,null", "required", "def,null"},
+		{"def,und", "null,und", "und", "def,null,und"},
+		{"def,len>=1", "def,len<=5", "def,len=1..5", "def"},
+		{"def,len==1", "def,len==3", "", "def,len=1..3"},
+		{"def,len=1..3", "def,len=2..6", "def,len=2..3", "def,len=1..6"},
+		{"def,values:nonnull", "def,values:nonnull,values:unique", "def,values:nonnull,values:unique", "def,values:nonnull"},
+		{"def,group=g:exactly-one", "def,requires=A", "def,group=g:exactly-one,requires=A", "def"},
+		{"def,group=g:exactly-one", "def,group=g:at-most-one", "", "def"},
+		{"def", "null", "", "def,null"},
+	} {
+		a, b := parse(tc.a), parse(tc.b)
+		intersect, err := a.Intersect(b)
+		if tc.intersect == "" {
+			assert.ErrorIs(t, err, undtag.ErrIncompatibleOption, "a = %s, b = %s", tc.a, tc.b)
+		} else {
+			assert.NilError(t, err)
+			assert.Equal(t, tc.intersect, intersect.String(), "a = %s, b = %s", tc.a, tc.b)
+		}
+		assert.Equal(t, tc.union, a.Union(b).String(), "a = %s, b = %s", tc.a, tc.b)
+	}
+}
@@ -0,0 +1,232 @@
+package undtag
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrMalformedConstraint is returned by [ParseOption]
+	// if the argument of a registered constraint could not be parsed.
+	ErrMalformedConstraint = errors.New("malformed constraint")
+	// ErrAlreadyRegistered is returned by [RegisterConstraint] and [RegisterPreset]
+	// if the name is already registered or reserved for built-in options.
+	ErrAlreadyRegistered = errors.New("already registered")
+)
+
+// State is a state of a value passed to [Constraint].
+type State int
+
+const (
+	// StateUndefined is undefined, or none for option types.
+	StateUndefined State = iota
+	StateNull
+	StateDefined
+)
+
+func (s State) String() string {
+	switch s {
+	case StateNull:
+		return "null"
+	case StateDefined:
+		return "defined"
+	default:
+		return "undefined"
+	}
+}
+
+// Constraint is an application defined constraint for the `und` struct tag.
+//
+// Constraints are registered by [RegisterConstraint] and evaluated by ../validate
+// after the value satisfies built-in options.
+type Constraint interface {
+	// Describe describes the constraint, e.g. "must be one of a, b". It is used in error messages.
+	Describe() string
+	// Valid reports whether value satisfies the constraint.
+	//
+	// value is the value stored in the field, e.g. T for und.Und[T], and is nil unless state is StateDefined.
+	// For elastic types, Valid is called for each element; null elements are passed as StateNull.
+	// For plain pointers, slices and maps, nil is StateUndefined and value is the pointed value or the field itself.
+	Valid(value any, state State) bool
+}
+
+// ConstraintParser parses the argument of a constraint, e.g. "a|b" for `und:"oneof=a|b"`.
+// arg is empty if the tag has no "=".
+type ConstraintParser func(arg string) (Constraint, error)
+
+// CustomOption is a constraint parsed from the `und` struct tag.
+type CustomOption struct {
+	Name       string
+	Arg        string
+	Constraint Constraint
+}
+
+var registry = struct {
+	sync.RWMutex
+	constraints map[string]ConstraintParser
+	presets     map[string]string
+}{
+	constraints: map[string]ConstraintParser{},
+	presets:     map[string]string{},
+}
+
+func isReserved(name string) bool {
+	switch name {
+	case UndTagValueRequired, UndTagValueNullish, UndTagValueDef, UndTagValueNull, UndTagValueUnd,
+		UndTagValueLen, UndTagValueValues, UndTagValueGroup, UndTagValueRequires:
+		return true
+	}
+	return false
+}
+
+// RegisterConstraint registers a constraint used as `und:"name"` or `und:"name=arg"`.
+//
+// name must not contain any of ",=@:;" and must not be one of built-in options.
+// Since validators are cached per type, constraints should be registered before validation, e.g. in init.
+func RegisterConstraint(name string, parse ConstraintParser) error {
+	if name == "" || strings.ContainsAny(name, ",=@:;") {
+		return fmt.Errorf("invalid constraint name %q", name)
+	}
+	if isReserved(name) || strings.HasPrefix(name, UndTagValueLen) || strings.HasPrefix(name, UndTagValueValues) {
+		return fmt.Errorf("%w: %q is reserved", ErrAlreadyRegistered, name)
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.constraints[name]; ok {
+		return fmt.Errorf("%w: %q", ErrAlreadyRegistered, name)
+	}
+	registry.constraints[name] = parse
+	return nil
+}
+
+// RegisterPreset registers a named set of options used as `und:"@name"`,
+// e.g. RegisterPreset("patchable", "def,null,und").
+//
+// options are validated by [ParseOption] at registration.
+// Presets can be combined with other options, e.g. `und:"@patchable,len>=1"`.
+// options may refer to presets already registered, e.g. RegisterPreset("nonempty", "@patchable,len>=1"),
+// which are expanded recursively.
+func RegisterPreset(name string, options string) error {
+	if name == "" || strings.ContainsAny(name, ",=@:;") {
+		return fmt.Errorf("invalid preset name %q", name)
+	}
+	if _, err := ParseOption(options); err != nil {
+		return fmt.Errorf("preset %q: %w", name, err)
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.presets[name]; ok {
+		return fmt.Errorf("%w: @%s", ErrAlreadyRegistered, name)
+	}
+	registry.presets[name] = options
+	return nil
+}
+
+func lookupConstraint(name string) (ConstraintParser, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	p, ok := registry.constraints[name]
+	return p, ok
+}
+
+func constraintNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return slices.Sorted(maps.Keys(registry.constraints))
+}
+
+func presetNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return slices.Sorted(maps.Keys(registry.presets))
+}
+
+func lookupPreset(name string) (string, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	p, ok := registry.presets[name]
+	return p, ok
+}
+
+// token is an option in `und` struct tag.
+type token struct {
+	text string
+	// offset is the byte offset of text in the tag.
+	// Options expanded from a preset have the offset of the preset.
+	offset int
+}
+
+// tokenize splits s into options, replacing @name with registered options.
+// Presets referring to other presets are expanded recursively.
+func tokenize(s string) ([]token, error) {
+	org := s
+	var (
+		tokens []token
+		opt    string
+		offset int
+	)
+	for len(s) > 0 {
+		opt, s, _ = strings.Cut(s, ",")
+		if name, ok := strings.CutPrefix(opt, "@"); ok {
+			expanded, err := expandPreset(name, nil)
+			if err != nil {
+				return nil, newParseError(org, token{opt, offset}, err)
+			}
+			for _, p := range expanded {
+				tokens = append(tokens, token{p, offset})
+			}
+		} else {
+			tokens = append(tokens, token{opt, offset})
+		}
+		offset += len(opt) + 1
+	}
+	return tokens, nil
+}
+
+// expandPreset returns options of the preset name, expanding presets in it.
+// visiting holds names of presets being expanded, to detect cycles.
+func expandPreset(name string, visiting []string) ([]string, error) {
+	if slices.Contains(visiting, name) {
+		return nil, fmt.Errorf("%w: preset cycle @%s", ErrUnknownOption, strings.Join(append(visiting, name), " -> @"))
+	}
+	preset, ok := lookupPreset(name)
+	if !ok {
+		if len(visiting) > 0 {
+			return nil, fmt.Errorf("%w: unknown preset @%s in @%s", ErrUnknownOption, name, visiting[len(visiting)-1])
+		}
+		return nil, fmt.Errorf("%w: unknown preset", ErrUnknownOption)
+	}
+	var opts []string
+	for _, opt := range strings.Split(preset, ",") {
+		inner, ok := strings.CutPrefix(opt, "@")
+		if !ok {
+			opts = append(opts, opt)
+			continue
+		}
+		expanded, err := expandPreset(inner, append(visiting, name))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, expanded...)
+	}
+	return opts, nil
+}
+
+// parseCustom parses opt as a registered constraint.
+// ok is false if no constraint is registered for the name.
+func parseCustom(opt string) (custom CustomOption, ok bool, err error) {
+	name, arg, _ := strings.Cut(opt, "=")
+	parse, ok := lookupConstraint(name)
+	if !ok {
+		return CustomOption{}, false, nil
+	}
+	c, err := parse(arg)
+	if err != nil {
+		return CustomOption{}, true, fmt.Errorf("%w: %s: %w", ErrMalformedConstraint, opt, err)
+	}
+	return CustomOption{Name: name, Arg: arg, Constraint: c}, true, nil
+}
@@ -0,0 +1,152 @@
+package undtag
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ParseError describes a malformed `und` struct tag.
+//
+// ParseError wraps one of sentinel errors of this package, e.g. [ErrUnknownOption] or [ErrMalformedLen],
+// so errors.Is can be used against it.
+type ParseError struct {
+	// Tag is the value of `und` struct tag.
+	Tag string
+	// Token is the offending option.
+	Token string
+	// Offset is the byte offset of Token in Tag.
+	// For options expanded from a preset, it is the offset of the preset.
+	Offset int
+	// Field is the name of the field which the tag is placed on.
+	// It is set by callers knowing the field, e.g. ../validate, and is empty if unknown.
+	Field string
+	// Suggestion is a valid option similar to Token, e.g. "required" for "requried".
+	// It is empty if no similar option is found.
+	Suggestion string
+	// Err is the cause.
+	Err error
+}
+
+func newParseError(tag string, tok token, err error) *ParseError {
+	pErr := &ParseError{
+		Tag:    tag,
+		Token:  tok.text,
+		Offset: tok.offset,
+		Err:    err,
+	}
+	if tok.text != "" && !errors.Is(err, ErrMultipleOption) {
+		if s := suggest(tok.text); s != tok.text {
+			pErr.Suggestion = s
+		}
+	}
+	return pErr
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	if e.Field != "" {
+		fmt.Fprintf(&b, "field %s: ", e.Field)
+	}
+	fmt.Fprintf(&b, "und tag %q", e.Tag)
+	if e.Token != "" {
+		fmt.Fprintf(&b, ": option %q at offset %d", e.Token, e.Offset)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	if e.Suggestion != "" {
+		fmt.Fprintf(&b, " (did you mean %q?)", e.Suggestion)
+	}
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// WithField returns a copy of e whose Field is set to field.
+func (e *ParseError) WithField(field string) *ParseError {
+	cloned := *e
+	cloned.Field = field
+	return &cloned
+}
+
+// suggest returns an option similar to opt, or an empty string if none is similar enough.
+func suggest(opt string) string {
+	if name, ok := strings.CutPrefix(opt, "@"); ok {
+		return closest(name, presetNames(), "@")
+	}
+	candidates := []string{
+		UndTagValueRequired, UndTagValueNullish, UndTagValueDef, UndTagValueNull, UndTagValueUnd,
+		"values:nonnull", "values:unique", "values:nonzero", "values:sorted",
+	}
+	candidates = append(candidates, constraintNames()...)
+	if rest, ok := strings.CutPrefix(opt, UndTagValueValues+":"); ok {
+		// values:nonull
+		if s := closest(rest, []string{"nonnull", "unique", "nonzero", "sorted"}, UndTagValueValues+":"); s != "" {
+			return s
+		}
+	}
+	if name, kind, ok := strings.Cut(opt, ":"); ok && strings.HasPrefix(name, UndTagValueGroup+"=") {
+		// group=name:exactly-on
+		kinds := []string{"exactly-one", "at-least-one", "at-most-one", "all-or-none"}
+		return closest(kind, kinds, name+":")
+	}
+	name, arg, hasArg := strings.Cut(opt, "=")
+	s := closest(name, candidates, "")
+	if s == "" {
+		// nonnull for values:nonnull
+		s = closest(UndTagValueValues+":"+name, candidates, "")
+	}
+	if s != "" && hasArg && slices.Contains(constraintNames(), s) {
+		s += "=" + arg
+	}
+	return s
+}
+
+// closest returns the candidate nearest to s prefixed with prefix.
+// Candidates farther than a third of its length (at least 1, at most 3) are not considered.
+func closest(s string, candidates []string, prefix string) string {
+	var (
+		best     string
+		bestDist = -1
+	)
+	for _, c := range candidates {
+		d := editDistance(s, c)
+		if d > min(max(len(c)/3, 1), 3) {
+			continue
+		}
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return prefix + best
+}
+
+// editDistance returns the Damerau-Levenshtein distance (optimal string alignment) between a and b.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -0,0 +1,49 @@
+package undtag_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ngicks/und/undtag"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseError(t *testing.T) {
+	for _, tc := range []struct {
+		tag        string
+		sentinel   error
+		token      string
+		offset     int
+		suggestion string
+	}{
+		{"", undtag.ErrEmptyOption, "", 0, ""},
+		{"def,requried", undtag.ErrUnknownOption, "requried", 4, "required"},
+		{"def,und,values:nonull", undtag.ErrMalformedValues, "values:nonull", 8, "values:nonnull"},
+		{"nonnull", undtag.ErrUnknownOption, "nonnull", 0, "values:nonnull"},
+		{"nul,und", undtag.ErrUnknownOption, "nul", 0, "null"},
+		{"def,len=>1", undtag.ErrMalformedLen, "len=>1", 4, ""},
+		{"len==1,len==2", undtag.ErrMultipleOption, "len==2", 7, ""},
+		{"required,def", undtag.ErrMultipleOption, "def", 9, ""},
+		{"group=g:exactly-on", undtag.ErrMalformedGroup, "group=g:exactly-on", 0, "group=g:exactly-one"},
+		{"foo", undtag.ErrUnknownOption, "foo", 0, ""},
+		{"@unknown", undtag.ErrUnknownOption, "@unknown", 0, ""},
+	} {
+		_, err := undtag.ParseOption(tc.tag)
+		assert.ErrorIs(t, err, tc.sentinel, "tag = %q", tc.tag)
+		var pErr *undtag.ParseError
+		assert.Assert(t, errors.As(err, &pErr), "tag = %q", tc.tag)
+		assert.Equal(t, tc.tag, pErr.Tag)
+		assert.Equal(t, tc.token, pErr.Token, "tag = %q", tc.tag)
+		assert.Equal(t, tc.offset, pErr.Offset, "tag = %q", tc.tag)
+		assert.Equal(t, tc.suggestion, pErr.Suggestion, "tag = %q", tc.tag)
+	}
+
+	_, err := undtag.ParseOption("def,requried")
+	assert.Error(
+		t,
+		err.(*undtag.ParseError).WithField("Foo"),
+		`field Foo: und tag "def,requried": option "requried" at offset 4: unknown option (did you mean "required"?)`,
+	)
+	_, err = undtag.ParseOption("len>x")
+	assert.ErrorContains(t, err, `length "x" in len>x is not an unsigned integer`)
+}
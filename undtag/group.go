@@ -0,0 +1,139 @@
+package undtag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Makes the field a member of a named group of fields.
+	// The value must be formatted as group=name:kind, where kind is one of
+	// exactly-one, at-least-one, at-most-one or all-or-none.
+	// Groups are evaluated at the struct level; the number of defined members must satisfy kind.
+	//
+	// example:
+	// type Sample struct {
+	// 	Card option.Option[string] `und:"def,und,group=payment:exactly-one"`
+	// 	Bank option.Option[string] `und:"def,und,group=payment:exactly-one"`
+	// }
+	UndTagValueGroup = "group"
+	// The field requires another field in the same struct to be defined when it is defined.
+	// The value must be formatted as requires=Field, where Field is the Go field name or the encoded name of the field.
+	//
+	// example:
+	// type Sample struct {
+	// 	Street option.Option[string] `und:"def,und,requires=City"`
+	// 	City   option.Option[string] `und:"def,und"`
+	// }
+	UndTagValueRequires = "requires"
+)
+
+// ErrMalformedGroup is returned by [ParseOption] if group or requires option is malformed.
+var ErrMalformedGroup = errors.New("malformed group")
+
+// GroupKind is a rule of a group of fields.
+type GroupKind int
+
+const (
+	GroupExactlyOne GroupKind = iota + 1
+	GroupAtLeastOne
+	GroupAtMostOne
+	GroupAllOrNone
+)
+
+func (k GroupKind) String() string {
+	switch k {
+	case GroupExactlyOne:
+		return "exactly-one"
+	case GroupAtLeastOne:
+		return "at-least-one"
+	case GroupAtMostOne:
+		return "at-most-one"
+	case GroupAllOrNone:
+		return "all-or-none"
+	}
+	return fmt.Sprintf("GroupKind(%d)", int(k))
+}
+
+// Describe describes the rule, e.g. "exactly one of".
+func (k GroupKind) Describe() string {
+	switch k {
+	case GroupExactlyOne:
+		return "exactly one of"
+	case GroupAtLeastOne:
+		return "at least one of"
+	case GroupAtMostOne:
+		return "at most one of"
+	case GroupAllOrNone:
+		return "all or none of"
+	}
+	return k.String()
+}
+
+// Valid reports whether defined members out of total satisfy the rule.
+func (k GroupKind) Valid(defined, total int) bool {
+	switch k {
+	case GroupExactlyOne:
+		return defined == 1
+	case GroupAtLeastOne:
+		return defined >= 1
+	case GroupAtMostOne:
+		return defined <= 1
+	case GroupAllOrNone:
+		return defined == 0 || defined == total
+	}
+	return true
+}
+
+// GroupOption is a group option parsed from the `und` struct tag.
+type GroupOption struct {
+	Name string
+	Kind GroupKind
+}
+
+// ParseGroup parses s formatted as group=name:kind.
+func ParseGroup(s string) (GroupOption, error) {
+	org := s
+	s, ok := strings.CutPrefix(s, UndTagValueGroup+"=")
+	if !ok {
+		return GroupOption{}, fmt.Errorf("%w: %s", ErrMalformedGroup, org)
+	}
+	name, kind, ok := strings.Cut(s, ":")
+	if !ok || name == "" {
+		return GroupOption{}, fmt.Errorf("%w: %s", ErrMalformedGroup, org)
+	}
+	g := GroupOption{Name: name}
+	switch kind {
+	case "exactly-one":
+		g.Kind = GroupExactlyOne
+	case "at-least-one":
+		g.Kind = GroupAtLeastOne
+	case "at-most-one":
+		g.Kind = GroupAtMostOne
+	case "all-or-none":
+		g.Kind = GroupAllOrNone
+	default:
+		return GroupOption{}, fmt.Errorf("%w: unknown kind: %s", ErrMalformedGroup, org)
+	}
+	return g, nil
+}
+
+// ParseRequires parses s formatted as requires=Field.
+func ParseRequires(s string) (string, error) {
+	field, ok := strings.CutPrefix(s, UndTagValueRequires+"=")
+	if !ok || field == "" {
+		return "", fmt.Errorf("%w: %s", ErrMalformedGroup, s)
+	}
+	return field, nil
+}
+
+// Groups returns group options in the order of appearance.
+func (u UndOpt) Groups() []GroupOption {
+	return u.groups
+}
+
+// Requires returns names of fields specified by requires options in the order of appearance.
+func (u UndOpt) Requires() []string {
+	return u.requires
+}
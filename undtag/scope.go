@@ -0,0 +1,169 @@
+package undtag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrMalformedScope is returned by [ParseScopes]
+	// if a scoped `und` struct tag has an empty segment, an empty scope name or a duplicate scope.
+	ErrMalformedScope = errors.New("malformed scope")
+	// ErrUnknownScope is returned by UndValidate and UndCheck
+	// if a scoped `und` struct tag has a scope not allowed by validate.AllowedScopes.
+	ErrUnknownScope = errors.New("unknown scope")
+)
+
+// ScopedOption selects options for scope from s, the value of `und` struct tag.
+//
+// s may be divided into segments by ";". A segment prefixed with "scope:" applies only to that scope,
+// and a segment without the prefix applies to any scope lacking its own segment, including the empty scope.
+//
+//	`und:"create:required;update:def,null,und"` // required for create, def,null,und for update, nothing otherwise.
+//	`und:"def,und;create:required"`             // required for create, def,und otherwise.
+//
+// A scope name consists of letters, digits, '_' and '-'. "values" can not be a scope name
+// since it conflicts with values option, e.g. values:nonnull.
+//
+// ScopedOption returns an empty string if no segment applies to scope.
+// If s has neither ";" nor a scope prefix, s is returned as is.
+func ScopedOption(s string, scope string) string {
+	opts, _ := selectScope(s, scope)
+	return opts
+}
+
+// selectScope is [ScopedOption] but also returns the byte offset of selected options in s.
+func selectScope(s string, scope string) (opts string, offset int) {
+	var (
+		defaultSeg    string
+		defaultOffset int
+		segOffset     int
+	)
+	for segOffset < len(s) {
+		seg, _, _ := strings.Cut(s[segOffset:], ";")
+		name, opts, ok := cutScope(seg)
+		if !ok {
+			defaultSeg, defaultOffset = seg, segOffset
+		} else if scope != "" && name == scope {
+			return opts, segOffset + len(name) + 1
+		}
+		segOffset += len(seg) + 1
+	}
+	return defaultSeg, defaultOffset
+}
+
+// ParseOptionScope is [ParseOption] for options selected by [ScopedOption].
+// It returns an error wrapping [ErrEmptyOption] if no segment applies to scope.
+//
+// Tag and Offset of a returned *[ParseError] refer to s, not to the selected options.
+// Other segments are not parsed; use [ParseScopes] to check all of them.
+func ParseOptionScope(s string, scope string) (UndOpt, error) {
+	opts, offset := selectScope(s, scope)
+	opt, err := ParseOption(opts)
+	if err != nil {
+		return UndOpt{}, shiftParseError(err, s, offset)
+	}
+	return opt, nil
+}
+
+// ParseScopes parses every segment of s, the value of `und` struct tag, and returns options keyed by scope names.
+// Options of the unscoped segment are keyed by an empty string.
+// A scope with an empty segment, e.g. "create:", is present with the zero UndOpt, meaning no option.
+//
+// Unlike [ScopedOption], which only selects a segment, ParseScopes returns an error wrapping [ErrMalformedScope]
+// for empty segments, empty scope names, duplicate scopes and multiple unscoped segments,
+// or a *[ParseError] for a malformed segment. Tag and Offset of the *ParseError refer to s, not to the segment.
+func ParseScopes(s string) (map[string]UndOpt, error) {
+	segs, err := splitScopes(s)
+	if err != nil {
+		return nil, err
+	}
+	scopes := make(map[string]UndOpt, len(segs))
+	for _, seg := range segs {
+		if seg.opts == "" {
+			scopes[seg.scope] = UndOpt{}
+			continue
+		}
+		opt, err := ParseOption(seg.opts)
+		if err != nil {
+			return nil, shiftParseError(err, s, seg.offset)
+		}
+		scopes[seg.scope] = opt
+	}
+	return scopes, nil
+}
+
+// segment is a segment of a scoped `und` struct tag.
+type segment struct {
+	scope string // empty for the unscoped segment.
+	opts  string
+	// offset is the byte offset of opts in the tag.
+	offset int
+}
+
+// splitScopes splits s into segments. It does not parse options in segments.
+func splitScopes(s string) ([]segment, error) {
+	var (
+		segs   []segment
+		offset int
+	)
+	for {
+		seg, rest, more := strings.Cut(s[offset:], ";")
+		malformed := func(format string, args ...any) error {
+			return newParseError(s, token{text: seg, offset: offset}, fmt.Errorf("%w: "+format, append([]any{ErrMalformedScope}, args...)...))
+		}
+		if seg == "" {
+			return nil, malformed("empty segment")
+		}
+		if strings.HasPrefix(seg, ":") {
+			return nil, malformed("empty scope name")
+		}
+		name, opts, ok := cutScope(seg)
+		optsOffset := offset
+		if ok {
+			optsOffset += len(name) + 1
+		}
+		for _, other := range segs {
+			if other.scope == name {
+				if name == "" {
+					return nil, malformed("multiple unscoped segments")
+				}
+				return nil, malformed("duplicate scope %q", name)
+			}
+		}
+		segs = append(segs, segment{scope: name, opts: opts, offset: optsOffset})
+		if !more {
+			return segs, nil
+		}
+		offset = len(s) - len(rest)
+	}
+}
+
+// shiftParseError adjusts err, returned from [ParseOption] for a segment at offset of tag, to refer to tag.
+func shiftParseError(err error, tag string, offset int) error {
+	var pErr *ParseError
+	if !errors.As(err, &pErr) {
+		return err
+	}
+	shifted := *pErr
+	shifted.Tag = tag
+	shifted.Offset += offset
+	return &shifted
+}
+
+// cutScope cuts the scope prefix of seg.
+func cutScope(seg string) (scope, opts string, ok bool) {
+	scope, opts, ok = strings.Cut(seg, ":")
+	if !ok || scope == "" || scope == UndTagValueValues {
+		return "", seg, false
+	}
+	for _, r := range scope {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '_', r == '-':
+		default:
+			return "", seg, false
+		}
+	}
+	return scope, opts, true
+}
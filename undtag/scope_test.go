@@ -0,0 +1,84 @@
+package undtag_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ngicks/und/undtag"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := undtag.ParseScopes("def,und;create:required;update:values:nonnull;delete:")
+	assert.NilError(t, err)
+	var names []string
+	for name := range scopes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	assert.DeepEqual(t, []string{"", "create", "delete", "update"}, names)
+	assert.Equal(t, "def,und", scopes[""].String())
+	assert.Equal(t, "required", scopes["create"].String())
+	assert.Equal(t, "values:nonnull", scopes["update"].String())
+	assert.Equal(t, "", scopes["delete"].String())
+
+	scopes, err = undtag.ParseScopes("values:nonnull")
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(scopes))
+	assert.Equal(t, "values:nonnull", scopes[""].String())
+
+	for _, tc := range []struct {
+		tag      string
+		sentinel error
+		token    string
+		offset   int
+	}{
+		{"create:required;create:def", undtag.ErrMalformedScope, "create:def", 16},
+		{"def;und", undtag.ErrMalformedScope, "und", 4},
+		{":required", undtag.ErrMalformedScope, ":required", 0},
+		{"create:required;:def", undtag.ErrMalformedScope, ":def", 16},
+		{"create:required;;update:def", undtag.ErrMalformedScope, "", 16},
+		{"create:required;", undtag.ErrMalformedScope, "", 16},
+		{"create:required;update:foo", undtag.ErrUnknownOption, "foo", 23},
+		{"create:required;cre ate:def", undtag.ErrUnknownOption, "cre ate:def", 16},
+		{"def,und;update:len>=x", undtag.ErrMalformedLen, "len>=x", 15},
+	} {
+		_, err := undtag.ParseScopes(tc.tag)
+		assert.ErrorIs(t, err, tc.sentinel, "tag = %q", tc.tag)
+		var pErr *undtag.ParseError
+		assert.Assert(t, errors.As(err, &pErr), "tag = %q", tc.tag)
+		assert.Equal(t, tc.tag, pErr.Tag)
+		assert.Equal(t, tc.token, pErr.Token, "tag = %q", tc.tag)
+		assert.Equal(t, tc.offset, pErr.Offset, "tag = %q", tc.tag)
+	}
+}
+
+func TestParseOptionScope(t *testing.T) {
+	opt, err := undtag.ParseOptionScope("def,und;create:required;update:def,null,und", "create")
+	assert.NilError(t, err)
+	assert.Equal(t, "required", opt.String())
+	opt, err = undtag.ParseOptionScope("def,und;create:required;update:def,null,und", "delete")
+	assert.NilError(t, err)
+	assert.Equal(t, "def,und", opt.String())
+	_, err = undtag.ParseOptionScope("create:required", "update")
+	assert.ErrorIs(t, err, undtag.ErrEmptyOption)
+
+	for _, tc := range []struct {
+		tag, scope, token string
+		offset            int
+	}{
+		{"create:required;update:def,requried", "update", "requried", 27},
+		{"def,und;update:len>=x", "update", "len>=x", 15},
+		{"update:def;nul", "create", "nul", 11},
+		{"requried", "", "requried", 0},
+	} {
+		_, err := undtag.ParseOptionScope(tc.tag, tc.scope)
+		var pErr *undtag.ParseError
+		assert.Assert(t, errors.As(err, &pErr), "tag = %q", tc.tag)
+		assert.Equal(t, tc.tag, pErr.Tag)
+		assert.Equal(t, tc.token, pErr.Token)
+		assert.Equal(t, tc.offset, pErr.Offset)
+		assert.Equal(t, tc.token, tc.tag[pErr.Offset:pErr.Offset+len(pErr.Token)])
+	}
+}
@@ -1,8 +1,11 @@
 package undtag
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -66,18 +69,27 @@ const (
 	// The field's length will be evaluated as (length) (comparison operator) (n),
 	// e.g. if tag is len>12, field.Len() > 12 must return true.
 	//
+	// A range can be specified as len=n..m, where n and m are inclusive bounds,
+	// or by a pair of lower and upper bounds, e.g. len>=1,len<=5.
+	//
 	// can be combined with other options.
 	//
 	// example:
 	// type Sample struct {
 	// 	Foo string `und:"len==3"`
+	// 	Bar string `und:"len=1..5"`
 	// }
 	UndTagValueLen = "len"
 	// Only for elastic types.
 	//
-	// The value must be formatted as values:nonnull.
+	// The value must be formatted as values:nonnull, values:unique, values:nonzero or values:sorted.
+	// Multiple values options can be combined, e.g. values:nonnull,values:unique.
 	//
-	// nonnull value means its internal value must not have null.
+	//   - nonnull means its internal value must not have null.
+	//   - unique means its non-null values must not have duplicates. T must be comparable.
+	//   - nonzero means its non-null values must not be zero value of T.
+	//   - sorted means its non-null values must be sorted in ascending order.
+	//     T must be an integer, a floating point number or a string type.
 	//
 	// example:
 	// type Sample struct {
@@ -122,18 +134,24 @@ type OptionLike interface {
 }
 
 type UndOptExport struct {
-	States *StateValidator
-	Len    *LenValidator
-	Values *ValuesValidator
+	States   *StateValidator
+	Len      *LenValidator
+	Values   *ValuesValidator
+	Custom   []CustomOption
+	Groups   []GroupOption
+	Requires []string
 }
 
 func (o UndOptExport) Into() UndOpt {
 	// the outer code can not initialize UndOpt itself since it uses internal package.
 	// Export type can not rely on Option like types.
 	return UndOpt{
-		states: option.FromPointer(o.States),
-		len:    option.FromPointer(o.Len),
-		values: option.FromPointer(o.Values),
+		states:   option.FromPointer(o.States),
+		len:      option.FromPointer(o.Len),
+		values:   option.FromPointer(o.Values),
+		custom:   slices.Clone(o.Custom),
+		groups:   slices.Clone(o.Groups),
+		requires: slices.Clone(o.Requires),
 	}
 }
 
@@ -143,27 +161,48 @@ type UndOpt struct {
 	states option.Option[StateValidator]
 	len    option.Option[LenValidator]
 	values option.Option[ValuesValidator]
+	custom []CustomOption
+	// groups and requires are evaluated at the struct level.
+	groups   []GroupOption
+	requires []string
 }
 
+// ParseOption parses s, the value of `und` struct tag.
+//
+// Besides built-in options, s may contain constraints registered by [RegisterConstraint]
+// and presets registered by [RegisterPreset].
+//
+// Errors are returned as *[ParseError] which tells which option is malformed.
+// They wrap sentinel errors, e.g. [ErrUnknownOption], so that errors.Is can be used against them.
 func ParseOption(s string) (UndOpt, error) {
+	org := s
 	if s == "" {
-		return UndOpt{}, ErrEmptyOption
+		return UndOpt{}, newParseError(org, token{}, ErrEmptyOption)
+	}
+	tokens, err := tokenize(s)
+	if err != nil {
+		return UndOpt{}, err
 	}
-	org := s
 	var (
-		opt         string
 		sawStateOpt bool
 		opts        UndOpt
 	)
-	for len(s) > 0 {
-		opt, s, _ = strings.Cut(s, ",")
+	for _, tok := range tokens {
+		opt := tok.text
 		if strings.HasPrefix(opt, UndTagValueLen) {
-			if opts.len.IsSome() {
-				return UndOpt{}, fmt.Errorf("%w: %s", ErrMultipleOption, org)
-			}
 			lenV, err := ParseLen(opt)
 			if err != nil {
-				return UndOpt{}, fmt.Errorf("%w: %w", ErrMalformedLen, err)
+				return UndOpt{}, newParseError(org, tok, fmt.Errorf("%w: %w", ErrMalformedLen, err))
+			}
+			if l, ok := opts.len.Get(); ok {
+				var merged bool
+				lenV, merged, err = l.merge(lenV)
+				if err != nil {
+					return UndOpt{}, newParseError(org, tok, fmt.Errorf("%w: %w", ErrMalformedLen, err))
+				}
+				if !merged {
+					return UndOpt{}, newParseError(org, tok, fmt.Errorf("%w: len is already specified as %s", ErrMultipleOption, l))
+				}
 			}
 			opts.states = opts.states.
 				Or(option.Some(StateValidator{})).
@@ -172,13 +211,40 @@ func ParseOption(s string) (UndOpt, error) {
 			continue
 		}
 
-		if strings.HasPrefix(opt, UndTagValueValues) {
-			if opts.values.IsSome() {
-				return UndOpt{}, fmt.Errorf("%w: %s", ErrMultipleOption, org)
+		if strings.HasPrefix(opt, UndTagValueGroup+"=") {
+			g, err := ParseGroup(opt)
+			if err != nil {
+				return UndOpt{}, newParseError(org, tok, err)
+			}
+			for _, gg := range opts.groups {
+				if gg.Name == g.Name {
+					return UndOpt{}, newParseError(org, tok, fmt.Errorf("%w: group %s is already specified", ErrMultipleOption, g.Name))
+				}
+			}
+			opts.groups = append(opts.groups, g)
+			continue
+		}
+
+		if strings.HasPrefix(opt, UndTagValueRequires+"=") {
+			field, err := ParseRequires(opt)
+			if err != nil {
+				return UndOpt{}, newParseError(org, tok, err)
 			}
+			opts.requires = append(opts.requires, field)
+			continue
+		}
+
+		if strings.HasPrefix(opt, UndTagValueValues) {
 			valuesV, err := ParseValues(opt)
 			if err != nil {
-				return UndOpt{}, fmt.Errorf("%w: %w", ErrMalformedValues, err)
+				return UndOpt{}, newParseError(org, tok, fmt.Errorf("%w: %w", ErrMalformedValues, err))
+			}
+			if v, ok := opts.values.Get(); ok {
+				var merged bool
+				valuesV, merged = v.merge(valuesV)
+				if !merged {
+					return UndOpt{}, newParseError(org, tok, fmt.Errorf("%w: %s is already specified", ErrMultipleOption, opt))
+				}
 			}
 			opts.values = option.Some(valuesV)
 			continue
@@ -187,16 +253,29 @@ func ParseOption(s string) (UndOpt, error) {
 		switch opt {
 		case UndTagValueRequired, UndTagValueNullish:
 			if sawStateOpt {
-				return UndOpt{}, fmt.Errorf("%w: und tag contains multiple mutually exclusive options, tag = %s", ErrMultipleOption, org)
+				return UndOpt{}, newParseError(org, tok, fmt.Errorf("%w: %s is mutually exclusive to other state options", ErrMultipleOption, opt))
 			}
 		case UndTagValueDef, UndTagValueNull, UndTagValueUnd:
 			if opts.states.IsSomeAnd(func(s StateValidator) bool {
 				return s.filled || opt == UndTagValueDef && s.Def || opt == UndTagValueNull && s.Null || opt == UndTagValueUnd && s.Und
 			}) {
-				return UndOpt{}, fmt.Errorf("%w: und tag contains multiple mutually exclusive options, tag = %s", ErrMultipleOption, org)
+				return UndOpt{}, newParseError(org, tok, fmt.Errorf("%w: %s conflicts with other state options", ErrMultipleOption, opt))
 			}
 		default:
-			return UndOpt{}, ErrUnknownOption
+			custom, ok, err := parseCustom(opt)
+			if err != nil {
+				return UndOpt{}, newParseError(org, tok, err)
+			}
+			if !ok {
+				return UndOpt{}, newParseError(org, tok, ErrUnknownOption)
+			}
+			for _, c := range opts.custom {
+				if c.Name == custom.Name {
+					return UndOpt{}, newParseError(org, tok, fmt.Errorf("%w: %s is already specified", ErrMultipleOption, custom.Name))
+				}
+			}
+			opts.custom = append(opts.custom, custom)
+			continue
 		}
 
 		opts.states = opts.states.Or(option.Some(StateValidator{})).Map(func(v StateValidator) StateValidator {
@@ -221,6 +300,9 @@ func ParseOption(s string) (UndOpt, error) {
 		sawStateOpt = true
 	}
 
+	// len adds def, e.g. nullish,len==0 is def,null,und,len==0, not required,len==0.
+	opts.states = opts.states.Map(StateValidator.normalize)
+
 	return opts, nil
 }
 
@@ -236,6 +318,11 @@ func (u UndOpt) Values() option.Option[ValuesValidator] {
 	return u.values
 }
 
+// Custom returns constraints registered by [RegisterConstraint] in the order of appearance.
+func (u UndOpt) Custom() []CustomOption {
+	return u.custom
+}
+
 func (o UndOpt) Describe() string {
 	var builder strings.Builder
 
@@ -256,6 +343,9 @@ func (o UndOpt) Describe() string {
 	if o.values.IsSome() {
 		appendStr(o.values.Value())
 	}
+	for _, c := range o.custom {
+		appendStr(c.Constraint)
+	}
 
 	return builder.String()
 }
@@ -338,6 +428,14 @@ type StateValidator struct {
 	Und    bool
 }
 
+// normalize clears filled unless s is exactly required or nullish.
+func (s StateValidator) normalize() StateValidator {
+	if s.filled && s.Def == (s.Null || s.Und) {
+		s.filled = false
+	}
+	return s
+}
+
 func (s StateValidator) Valid(u UndLike) bool {
 	switch {
 	case u.IsDefined():
@@ -376,21 +474,25 @@ func (s StateValidator) Describe() string {
 	return "must be " + builder.String()
 }
 
+// LenValidator validates length of elastic values.
+//
+// If Op is LenOpRange, the length must be in Len..Max, both inclusive.
 type LenValidator struct {
 	Len int
-	Op  lenOp
+	Op  LenOp
+	Max int
 }
 
 func ParseLen(s string) (LenValidator, error) {
 	org := s
 	s, _ = strings.CutPrefix(s, UndTagValueLen)
 	if len(s) < 2 { // <n, at least 2.
-		return LenValidator{}, fmt.Errorf("unknown op: %s", org)
+		return LenValidator{}, fmt.Errorf("missing operator or length in %s", org)
 	}
 	var v LenValidator
 	switch {
 	default:
-		return LenValidator{}, fmt.Errorf("unknown op: %s", org)
+		return LenValidator{}, fmt.Errorf("unknown operator in %s: must be one of ==, >, >=, <, <= or =n..m", org)
 	case s[:2] == "==":
 		v.Op = LenOpEqEq
 	case s[:2] == ">=":
@@ -401,20 +503,108 @@ func ParseLen(s string) (LenValidator, error) {
 		v.Op = LenOpLe
 	case s[0] == '>':
 		v.Op = LenOpGr
+	case s[0] == '=':
+		return parseLenRange(s[1:], org)
 	}
 
 	s = s[v.Op.len():]
 
 	len, err := strconv.ParseUint(s, 10, 64)
 	if err != nil {
-		return LenValidator{}, fmt.Errorf("unknown len: %w", err)
+		return LenValidator{}, fmt.Errorf("length %q in %s is not an unsigned integer", s, org)
 	}
 
 	v.Len = int(len)
 	return v, nil
 }
 
+// parseLenRange parses n..m.
+func parseLenRange(s string, org string) (LenValidator, error) {
+	minS, maxS, ok := strings.Cut(s, "..")
+	if !ok {
+		return LenValidator{}, fmt.Errorf("unknown operator in %s: range must be formatted as len=n..m", org)
+	}
+	min, err := strconv.ParseUint(minS, 10, 64)
+	if err != nil {
+		return LenValidator{}, fmt.Errorf("length %q in %s is not an unsigned integer", minS, org)
+	}
+	max, err := strconv.ParseUint(maxS, 10, 64)
+	if err != nil {
+		return LenValidator{}, fmt.Errorf("length %q in %s is not an unsigned integer", maxS, org)
+	}
+	return newLenRange(int(min), int(max))
+}
+
+func newLenRange(min, max int) (LenValidator, error) {
+	if min > max {
+		return LenValidator{}, fmt.Errorf("empty range: %d..%d", min, max)
+	}
+	return LenValidator{Len: min, Op: LenOpRange, Max: max}, nil
+}
+
+// bounds returns inclusive bounds of v. hasMin or hasMax is false if v does not bound that side.
+// ok is false if the upper bound is less than 0, i.e. v is len<0.
+func (v LenValidator) bounds() (min, max int, hasMin, hasMax, ok bool) {
+	switch v.Op {
+	case LenOpEqEq:
+		return v.Len, v.Len, true, true, true
+	case LenOpGr:
+		return v.Len + 1, 0, true, false, true
+	case LenOpGrEq:
+		return v.Len, 0, true, false, true
+	case LenOpLe:
+		return 0, v.Len - 1, false, true, v.Len > 0
+	case LenOpLeEq:
+		return 0, v.Len, false, true, true
+	case LenOpRange:
+		return v.Len, v.Max, true, true, true
+	}
+	return 0, 0, false, false, true
+}
+
+// UpperBound returns the maximum length allowed by v, inclusive.
+// ok is false if v does not bound the length from above, e.g. len>=1.
+// max is negative if no length is allowed, i.e. v is len<0.
+func (v LenValidator) UpperBound() (max int, ok bool) {
+	_, max, _, hasMax, _ := v.bounds()
+	return max, hasMax
+}
+
+// merge merges a lower bound and an upper bound into a range, e.g. len>=1 and len<=5 into len=1..5.
+// merged is false if v and u can not be merged, i.e. both bound a same side.
+func (v LenValidator) merge(u LenValidator) (merged LenValidator, ok bool, err error) {
+	vMin, vMax, vHasMin, vHasMax, vOk := v.bounds()
+	uMin, uMax, uHasMin, uHasMax, uOk := u.bounds()
+	if vHasMin == uHasMin || vHasMax == uHasMax {
+		return LenValidator{}, false, nil
+	}
+	if !vOk || !uOk {
+		return LenValidator{}, false, fmt.Errorf("empty range: %s and %s", v, u)
+	}
+	if vHasMin {
+		merged, err = newLenRange(vMin, uMax)
+	} else {
+		merged, err = newLenRange(uMin, vMax)
+	}
+	if err != nil {
+		return LenValidator{}, false, err
+	}
+	return merged, true, nil
+}
+
+// String returns v in the form of len option, e.g. len>=1 or len=1..5.
+func (v LenValidator) String() string {
+	if v.Op == LenOpRange {
+		return UndTagValueLen + "=" + strconv.FormatInt(int64(v.Len), 10) + ".." + strconv.FormatInt(int64(v.Max), 10)
+	}
+	return UndTagValueLen + v.Op.String() + strconv.FormatInt(int64(v.Len), 10)
+}
+
 func (v LenValidator) Describe() string {
+	if v.Op == LenOpRange {
+		return "defined or must have length of between " +
+			strconv.FormatInt(int64(v.Len), 10) + " and " + strconv.FormatInt(int64(v.Max), 10)
+	}
 	return "defined or must have length of " + v.Op.String() + " " + strconv.FormatInt(int64(v.Len), 10)
 }
 
@@ -422,20 +612,25 @@ func (v LenValidator) Valid(e ElasticLike) bool {
 	if v.Op == 0 {
 		return true
 	}
+	if v.Op == LenOpRange {
+		return v.Len <= e.Len() && e.Len() <= v.Max
+	}
 	return v.Op.Compare(e.Len(), v.Len)
 }
 
-type lenOp int
+// LenOp is an operator of len option.
+type LenOp int
 
 const (
-	LenOpEqEq = lenOp(iota + 1) // ==
-	LenOpGr                     // >
-	LenOpGrEq                   // >=
-	LenOpLe                     // <
-	LenOpLeEq                   // <=
+	LenOpEqEq  = LenOp(iota + 1) // ==
+	LenOpGr                      // >
+	LenOpGrEq                    // >=
+	LenOpLe                      // <
+	LenOpLeEq                    // <=
+	LenOpRange                   // n..m
 )
 
-func (o lenOp) len() int {
+func (o LenOp) len() int {
 	switch o {
 	case LenOpLe, LenOpGr:
 		return 1
@@ -445,9 +640,9 @@ func (o lenOp) len() int {
 	return 0
 }
 
-func (o lenOp) String() string {
+func (o LenOp) String() string {
 	switch o {
-	default: // case lenOpEqEq:
+	default: // case LenOpEqEq:
 		return "=="
 	case LenOpGr:
 		return ">"
@@ -457,12 +652,15 @@ func (o lenOp) String() string {
 		return "<"
 	case LenOpLeEq:
 		return "<="
+	case LenOpRange:
+		return ".."
 	}
 }
 
-func (o lenOp) Compare(i, j int) bool {
+// Compare compares i and j. For LenOpRange, it reports i == j since a range needs two bounds.
+func (o LenOp) Compare(i, j int) bool {
 	switch o {
-	default: // case lenOpEqEq:
+	default: // case LenOpEqEq:
 		return i == j
 	case LenOpGr:
 		return i > j
@@ -477,13 +675,16 @@ func (o lenOp) Compare(i, j int) bool {
 
 type ValuesValidator struct {
 	Nonnull bool
+	Unique  bool
+	Nonzero bool
+	Sorted  bool
 }
 
 func ParseValues(s string) (ValuesValidator, error) {
 	org := s
 	s, _ = strings.CutPrefix(s, UndTagValueValues)
 	if len(s) < 2 || s[0] != ':' { // :nonull
-		return ValuesValidator{}, fmt.Errorf("unknown op: %s", org)
+		return ValuesValidator{}, fmt.Errorf("%s must be formatted as values:variant", org)
 	}
 
 	s = s[1:] // removes ':'
@@ -491,23 +692,162 @@ func ParseValues(s string) (ValuesValidator, error) {
 	switch s {
 	case "nonnull":
 		return ValuesValidator{Nonnull: true}, nil
+	case "unique":
+		return ValuesValidator{Unique: true}, nil
+	case "nonzero":
+		return ValuesValidator{Nonzero: true}, nil
+	case "sorted":
+		return ValuesValidator{Sorted: true}, nil
 	}
 
-	return ValuesValidator{}, fmt.Errorf("unknown op: %s", org)
+	return ValuesValidator{}, fmt.Errorf("unknown variant %q: must be one of nonnull, unique, nonzero or sorted", s)
+}
+
+// merge combines v and u. ok is false if they have a same option.
+func (v ValuesValidator) merge(u ValuesValidator) (merged ValuesValidator, ok bool) {
+	if v.Nonnull && u.Nonnull || v.Unique && u.Unique || v.Nonzero && u.Nonzero || v.Sorted && u.Sorted {
+		return ValuesValidator{}, false
+	}
+	return ValuesValidator{
+		Nonnull: v.Nonnull || u.Nonnull,
+		Unique:  v.Unique || u.Unique,
+		Nonzero: v.Nonzero || u.Nonzero,
+		Sorted:  v.Sorted || u.Sorted,
+	}, true
+}
+
+// needsElements reports whether v inspects each element rather than calling HasNull.
+func (v ValuesValidator) needsElements() bool {
+	return v.Unique || v.Nonzero || v.Sorted
+}
+
+// Check checks whether values of rt, the type of elements, supports options of v.
+// unique needs rt to be comparable and sorted needs rt to be ordered.
+// Pointer types are checked against the types they point to.
+func (v ValuesValidator) Check(rt reflect.Type) error {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if v.Unique && !rt.Comparable() {
+		return fmt.Errorf("%w: values:unique on non comparable type %s", ErrMalformedValues, rt)
+	}
+	if v.Sorted && !isOrdered(rt.Kind()) {
+		return fmt.Errorf("%w: values:sorted on non ordered type %s", ErrMalformedValues, rt)
+	}
+	return nil
 }
 
+// Valid reports whether e satisfies v.
+//
+// For unique, nonzero and sorted, e must have Pointers method returning []*T, like elastic types,
+// or Elements method returning reflect.Value of an array, a slice or a map.
+// Otherwise they are not validated.
 func (v ValuesValidator) Valid(e ElasticLike) bool {
-	switch {
-	case v.Nonnull:
-		return !e.HasNull()
+	if v.Nonnull && e.HasNull() {
+		return false
+	}
+	if !v.needsElements() {
+		return true
+	}
+	elems, ok := elementsOf(e)
+	if !ok {
+		return true
+	}
+	return v.ValidElements(elems)
+}
+
+func elementsOf(e ElasticLike) (reflect.Value, bool) {
+	if x, ok := e.(interface{ Elements() reflect.Value }); ok {
+		return x.Elements(), true
+	}
+	m := reflect.ValueOf(e).MethodByName("Pointers")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 || m.Type().Out(0).Kind() != reflect.Slice {
+		return reflect.Value{}, false
+	}
+	return m.Call(nil)[0], true
+}
+
+// ValidElements validates elems, an array, a slice or a map, against unique, nonzero and sorted options.
+// Elements may be pointers, where nil is treated as null and skipped.
+// nonnull is not validated by ValidElements.
+func (v ValuesValidator) ValidElements(elems reflect.Value) bool {
+	var (
+		seen map[any]struct{}
+		prev reflect.Value
+	)
+	if v.Unique {
+		seen = make(map[any]struct{})
+	}
+	for _, e := range elems.Seq2() {
+		for e.Kind() == reflect.Pointer || e.Kind() == reflect.Interface {
+			if e.IsNil() {
+				break
+			}
+			e = e.Elem()
+		}
+		if (e.Kind() == reflect.Pointer || e.Kind() == reflect.Interface) && e.IsNil() {
+			// null
+			continue
+		}
+		if v.Nonzero && e.IsZero() {
+			return false
+		}
+		if v.Unique && e.CanInterface() && e.Comparable() {
+			k := e.Interface()
+			if _, ok := seen[k]; ok {
+				return false
+			}
+			seen[k] = struct{}{}
+		}
+		if v.Sorted {
+			if prev.IsValid() && compareOrdered(prev, e) > 0 {
+				return false
+			}
+			prev = e
+		}
 	}
 	return true
 }
 
+func isOrdered(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+	return false
+}
+
+// compareOrdered compares i and j of a same ordered kind. It returns 0 for values of other kinds.
+func compareOrdered(i, j reflect.Value) int {
+	switch i.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(i.Int(), j.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(i.Uint(), j.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(i.Float(), j.Float())
+	case reflect.String:
+		return cmp.Compare(i.String(), j.String())
+	}
+	return 0
+}
+
 func (v ValuesValidator) Describe() string {
-	switch {
-	case v.Nonnull:
-		return "must not contain null"
+	var descs []string
+	if v.Nonnull {
+		descs = append(descs, "must not contain null")
+	}
+	if v.Unique {
+		descs = append(descs, "must not contain duplicate values")
+	}
+	if v.Nonzero {
+		descs = append(descs, "must not contain zero values")
+	}
+	if v.Sorted {
+		descs = append(descs, "must be sorted in ascending order")
 	}
-	return ""
+	return strings.Join(descs, ", and ")
 }
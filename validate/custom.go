@@ -0,0 +1,86 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/ngicks/und/internal/option"
+	"github.com/ngicks/und/undtag"
+)
+
+// validateCustom evaluates constraints registered by undtag.RegisterConstraint
+// against v, a value of an implementor type or nilValue, and fv, its reflect.Value.
+func validateCustom(opt undtag.UndOpt, v any, fv reflect.Value, w *walker) error {
+	if len(opt.Custom()) == 0 {
+		return nil
+	}
+	switch x := v.(type) {
+	case nilValue:
+		if x.null {
+			return validatePlainCustom(opt, nil, undtag.StateNull, w)
+		}
+		return validatePlainCustom(opt, nil, undtag.StateUndefined, w)
+	case ElasticLike:
+		switch {
+		case x.IsUndefined():
+			return validatePlainCustom(opt, nil, undtag.StateUndefined, w)
+		case x.IsNull():
+			return validatePlainCustom(opt, nil, undtag.StateNull, w)
+		}
+		m := fv.MethodByName("Pointers")
+		if !m.IsValid() {
+			return nil
+		}
+		ptrs := m.Call(nil)[0]
+		for i := range ptrs.Len() {
+			p := ptrs.Index(i)
+			w.push(fieldSelector{fieldSelectorTypeIndex, strconv.FormatInt(int64(i), 10)})
+			var err error
+			if p.IsNil() {
+				err = validatePlainCustom(opt, nil, undtag.StateNull, w)
+			} else {
+				err = validatePlainCustom(opt, p.Elem().Interface(), undtag.StateDefined, w)
+			}
+			w.pop()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case UndLike:
+		switch {
+		case x.IsUndefined():
+			return validatePlainCustom(opt, nil, undtag.StateUndefined, w)
+		case x.IsNull():
+			return validatePlainCustom(opt, nil, undtag.StateNull, w)
+		}
+	case OptionLike:
+		if x.IsNone() {
+			return validatePlainCustom(opt, nil, undtag.StateUndefined, w)
+		}
+	default:
+		return nil
+	}
+	m := fv.MethodByName("Value")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	return validatePlainCustom(opt, m.Call(nil)[0].Interface(), undtag.StateDefined, w)
+}
+
+// validatePlainCustom evaluates custom constraints in opt against value and state.
+// It reports the first violated constraint.
+func validatePlainCustom(opt undtag.UndOpt, value any, state undtag.State, w *walker) error {
+	for _, c := range opt.Custom() {
+		if !c.Constraint.Valid(value, state) {
+			return w.report(&ValidationError{
+				err:   fmt.Errorf("input %s", c.Constraint.Describe()),
+				code:  Code(c.Name),
+				opt:   option.Some(opt),
+				state: state.String(),
+			})
+		}
+	}
+	return nil
+}
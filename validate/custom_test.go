@@ -0,0 +1,214 @@
+package validate_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ngicks/und"
+	"github.com/ngicks/und/option"
+	"github.com/ngicks/und/sliceund/elastic"
+	"github.com/ngicks/und/undtag"
+	"github.com/ngicks/und/validate"
+	"gotest.tools/v3/assert"
+)
+
+type oneOf []string
+
+func (o oneOf) Describe() string {
+	return "must be one of " + strings.Join(o, ", ")
+}
+
+func (o oneOf) Valid(value any, state undtag.State) bool {
+	if state != undtag.StateDefined {
+		return true
+	}
+	s, ok := value.(string)
+	return ok && slices.Contains(o, s)
+}
+
+type lower struct{}
+
+func (lower) Describe() string { return "must be lower case" }
+
+func (lower) Valid(value any, state undtag.State) bool {
+	s, ok := value.(string)
+	return !ok || strings.ToLower(s) == s
+}
+
+func init() {
+	must := func(err error) {
+		if err != nil {
+			panic(err)
+		}
+	}
+	must(undtag.RegisterConstraint("oneof", func(arg string) (undtag.Constraint, error) {
+		if arg == "" {
+			return nil, errors.New("empty")
+		}
+		return oneOf(strings.Split(arg, "|")), nil
+	}))
+	must(undtag.RegisterConstraint("lower", func(arg string) (undtag.Constraint, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("unexpected arg %q", arg)
+		}
+		return lower{}, nil
+	}))
+	must(undtag.RegisterConstraint("positive", func(arg string) (undtag.Constraint, error) {
+		return positive{}, nil
+	}))
+	must(undtag.RegisterPreset("patchable", "def,null,und"))
+	must(undtag.RegisterPreset("patchable_lower", "@patchable,lower"))
+}
+
+type positive struct{}
+
+func (positive) Describe() string { return "must be positive" }
+
+func (positive) Valid(value any, state undtag.State) bool {
+	n, ok := value.(int)
+	return !ok || n > 0
+}
+
+type customConstraint struct {
+	O option.Option[string]   `json:"o" und:"def,oneof=a|b"`
+	U und.Und[string]         `json:"u" und:"@patchable,lower"`
+	E elastic.Elastic[string] `json:"e" und:"lower"`
+	P *string                 `json:"p" und:"oneof=x|y"`
+	S string                  `json:"s" und:"lower,oneof=foo|Bar"`
+}
+
+func TestValidate_custom_constraint(t *testing.T) {
+	x := "x"
+	v := customConstraint{
+		O: option.Some("a"),
+		U: und.Null[string](),
+		E: elastic.FromOptions(option.Some("foo"), option.None[string]()),
+		P: &x,
+		S: "foo",
+	}
+	assert.NilError(t, validate.UndCheck(v))
+	assert.NilError(t, validate.UndValidate(v))
+	assert.NilError(t, validate.UndValidate(customConstraint{O: option.Some("b"), S: "foo"}))
+
+	z := "z"
+	err := validate.UndValidate(
+		customConstraint{
+			O: option.Some("c"),
+			U: und.Defined("FOO"),
+			E: elastic.FromValues("foo", "Bar"),
+			P: &z,
+			S: "Bar",
+		},
+		validate.CollectAll(),
+	)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/o:oneof", "/u:lower", "/e/1:lower", "/p:oneof", "/s:lower"}, pointersAndCodes(err))
+	assert.ErrorContains(t, err, "validation failed at .o: input must be one of a, b")
+
+	err = validate.UndValidate(customConstraint{O: option.None[string](), S: "foo"})
+	assert.ErrorContains(t, err, "validation failed at .o: input must be defined")
+}
+
+type customScalar struct {
+	N int    `json:"n" und:"positive"`
+	R int    `json:"r" und:"required,positive"`
+	A any    `json:"a" und:"def"`
+	B bool   `json:"b" und:"lower"`
+	S string `json:"s"`
+}
+
+func TestValidate_custom_constraint_scalar(t *testing.T) {
+	assert.NilError(t, validate.UndCheck(customScalar{}))
+	assert.NilError(t, validate.UndValidate(customScalar{N: 1, R: 2, A: 0}))
+
+	err := validate.UndValidate(customScalar{N: 0, R: -1}, validate.CollectAll())
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/n:positive", "/r:positive", "/a:state"}, pointersAndCodes(err))
+	assert.ErrorContains(t, err, "validation failed at .n: input must be positive")
+}
+
+type (
+	invalidScalarTag struct {
+		N int `und:"requried"`
+	}
+	invalidScalarLen struct {
+		N int `und:"len>=1"`
+	}
+	invalidScalarFunc struct {
+		F func() `und:"def"`
+	}
+)
+
+func TestValidate_scalar_invalid_tag(t *testing.T) {
+	err := validate.UndCheck(invalidScalarTag{})
+	assert.ErrorIs(t, err, undtag.ErrUnknownOption)
+	assert.Equal(t, validate.CodeInvalidTag, validate.Errors(err)[0].Code())
+	assert.ErrorIs(t, validate.UndValidate(invalidScalarTag{N: 1}), undtag.ErrUnknownOption)
+
+	err = validate.UndCheck(invalidScalarLen{})
+	assert.ErrorContains(t, err, "len on int")
+	assert.Equal(t, validate.CodeInvalidTag, validate.Errors(err)[0].Code())
+
+	err = validate.UndCheck(invalidScalarFunc{})
+	assert.ErrorContains(t, err, "und tag on func()")
+	assert.Equal(t, validate.CodeInvalidTag, validate.Errors(err)[0].Code())
+}
+
+type (
+	invalidCustomArg struct {
+		A option.Option[string] `und:"oneof"`
+	}
+	invalidCustomMultiple struct {
+		A option.Option[string] `und:"lower,lower"`
+	}
+	invalidPreset struct {
+		A option.Option[string] `und:"@unknown"`
+	}
+	invalidPresetCombination struct {
+		A option.Option[string] `und:"@patchable,def"`
+	}
+)
+
+func TestValidate_custom_constraint_invalid(t *testing.T) {
+	err := validate.UndCheck(invalidCustomArg{})
+	assert.ErrorIs(t, err, undtag.ErrMalformedConstraint)
+	err = validate.UndCheck(invalidCustomMultiple{})
+	assert.ErrorIs(t, err, undtag.ErrMultipleOption)
+	err = validate.UndCheck(invalidPreset{})
+	assert.ErrorIs(t, err, undtag.ErrUnknownOption)
+	err = validate.UndCheck(invalidPresetCombination{})
+	assert.ErrorIs(t, err, undtag.ErrMultipleOption)
+
+	assert.ErrorIs(t, undtag.RegisterConstraint("oneof", nil), undtag.ErrAlreadyRegistered)
+	assert.ErrorIs(t, undtag.RegisterConstraint("required", nil), undtag.ErrAlreadyRegistered)
+	assert.ErrorIs(t, undtag.RegisterPreset("patchable", "def"), undtag.ErrAlreadyRegistered)
+	assert.ErrorIs(t, undtag.RegisterPreset("broken", "def,foo"), undtag.ErrUnknownOption)
+	assert.ErrorIs(t, undtag.RegisterPreset("broken", "@missing"), undtag.ErrUnknownOption)
+}
+
+func TestValidate_nested_preset(t *testing.T) {
+	opt, err := undtag.ParseOption("@patchable_lower")
+	assert.NilError(t, err)
+	assert.Equal(t, "def,null,und,lower", opt.String())
+
+	type sample struct {
+		A und.Und[string] `und:"@patchable_lower"`
+	}
+	assert.NilError(t, validate.UndValidate(sample{A: und.Null[string]()}))
+	assert.ErrorContains(t, validate.UndValidate(sample{A: und.Defined("Foo")}), "lower")
+}
+
+func TestValidate_custom_constraint_String(t *testing.T) {
+	for _, tag := range []string{"def,oneof=a|b", "@patchable,lower", "lower", "oneof=x|y", "lower,oneof=foo|Bar", "nullish,lower,len==2"} {
+		opt, err := undtag.ParseOption(tag)
+		assert.NilError(t, err)
+		reparsed, err := undtag.ParseOption(opt.String())
+		assert.NilError(t, err, "tag = %s, String() = %s", tag, opt.String())
+		assert.Assert(t, reflect.DeepEqual(opt.Export(), reparsed.Export()), "tag = %s, String() = %s", tag, opt.String())
+		assert.Equal(t, opt.String(), reparsed.String())
+	}
+}
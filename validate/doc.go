@@ -0,0 +1,216 @@
+package validate
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// FieldDoc documents a field of a struct and constraints placed on it by `und` struct tag.
+type FieldDoc struct {
+	// Path is a JSON pointer to the field from the root struct.
+	// Elements of arrays, slices, maps and elastic types are represented as "*", e.g. /items/*/id.
+	Path string `json:"path"`
+	// Type is the Go type of the field.
+	Type string `json:"type"`
+	// Kind is the kind of the data container type of the field, one of "option", "und", "elastic" or "plain".
+	// For arrays, slices and maps of data container types, it is the kind of elements, and Collection is set.
+	Kind string `json:"kind"`
+	// Collection is "array", "slice" or "map" if the tag is applied to elements of the field.
+	Collection string `json:"collection,omitempty"`
+	// Tag is the canonical form of the tag. See [undtag.UndOpt.String].
+	Tag string `json:"tag,omitempty"`
+	// States are allowed states, "defined", "null" and "undefined". Empty if any state is allowed.
+	// For option types, "defined" means some, and "null" and "undefined" mean none.
+	States []string `json:"states,omitempty"`
+	// Len is the len option, e.g. len>=1 or len=1..5.
+	Len string `json:"len,omitempty"`
+	// Values are values options, e.g. values:nonnull.
+	Values []string `json:"values,omitempty"`
+	// Description is a human-readable description of constraints. See [undtag.UndOpt.Describe].
+	Description string `json:"description,omitempty"`
+}
+
+// Document lists fields of rt, a struct type or a pointer to a struct type, with constraints placed by `und` struct tags.
+//
+// Fields are named and promoted in the same way as [UndValidate] does, and [FieldNameTag] is honored.
+// Structs nested in fields, pointers, collections and data container types are documented as well,
+// but recursive types are documented only once in a path.
+//
+// It returns an error if a tag is malformed, like [UndCheck].
+func Document(rt reflect.Type, opts ...ValidateOption) ([]FieldDoc, error) {
+	w := newWalker(opts)
+	if err := cacheValidator(rt, w.opts.tagConfig()).check(); err != nil {
+		return nil, err
+	}
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	var docs []FieldDoc
+	if err := documentStruct(rt, w.opts.tagConfig(), "", map[reflect.Type]bool{}, &docs); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// DocumentScope is like [Document] but documents constraints for scope. See [UndValidateScope].
+func DocumentScope(rt reflect.Type, scope string, opts ...ValidateOption) ([]FieldDoc, error) {
+	return Document(rt, append(opts, withScope(scope))...)
+}
+
+func documentStruct(rt reflect.Type, cfg tagConfig, prefix string, onPath map[reflect.Type]bool, docs *[]FieldDoc) error {
+	if onPath[rt] {
+		return nil
+	}
+	onPath[rt] = true
+	defer delete(onPath, rt)
+
+	for _, f := range visibleFields(rt, cfg.nameTag) {
+		path := prefix + "/" + escapePointer(f.name)
+		doc := FieldDoc{Path: path, Type: f.sf.Type.String(), Kind: "plain"}
+
+		ft := f.sf.Type
+		base := derefType(ft)
+		if kind := containerKind(base); kind != "" {
+			doc.Kind = kind
+		} else if c := collectionKind(base); c != "" {
+			if kind := containerKind(derefType(base.Elem())); kind != "" {
+				doc.Kind, doc.Collection = kind, c
+			}
+		}
+
+		if err := cfg.checkScopes(f.sf); err != nil {
+			return newTagError(err, f.name)
+		}
+		if cfg.undTag(f.sf) != "" {
+			opt, err := cfg.parseUndTag(f.sf)
+			if err != nil {
+				return newTagError(err, f.name)
+			}
+			doc.Tag = opt.String()
+			doc.Description = opt.Describe()
+			if states, ok := opt.LookupStates(); ok {
+				if states.Def {
+					doc.States = append(doc.States, "defined")
+				}
+				if states.Null {
+					doc.States = append(doc.States, "null")
+				}
+				if states.Und {
+					doc.States = append(doc.States, "undefined")
+				}
+			}
+			if l, ok := opt.LookupLen(); ok {
+				doc.Len = l.String()
+			}
+			if v, ok := opt.LookupValues(); ok {
+				doc.Values = strings.Split(v.String(), ",")
+			}
+		}
+		*docs = append(*docs, doc)
+
+		if err := documentValue(ft, cfg, path, onPath, docs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// documentValue documents structs stored in values of rt.
+func documentValue(rt reflect.Type, cfg tagConfig, path string, onPath map[reflect.Type]bool, docs *[]FieldDoc) error {
+	rt = derefType(rt)
+	if isContainer(rt) {
+		elem, isElastic := containerElem(rt)
+		if elem == nil {
+			return nil
+		}
+		if isElastic {
+			path += "/*"
+		}
+		return documentValue(elem, cfg, path, onPath, docs)
+	}
+	switch rt.Kind() {
+	case reflect.Struct:
+		return documentStruct(rt, cfg, path, onPath, docs)
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return documentValue(rt.Elem(), cfg, path+"/*", onPath, docs)
+	}
+	return nil
+}
+
+func derefType(rt reflect.Type) reflect.Type {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return rt
+}
+
+func containerKind(rt reflect.Type) string {
+	switch {
+	case rt.Implements(elasticLike):
+		return "elastic"
+	case rt.Implements(undLikeTy):
+		return "und"
+	case rt.Implements(optionLikeTy):
+		return "option"
+	}
+	return ""
+}
+
+func collectionKind(rt reflect.Type) string {
+	switch rt.Kind() {
+	case reflect.Array:
+		return "array"
+	case reflect.Slice:
+		return "slice"
+	case reflect.Map:
+		return "map"
+	}
+	return ""
+}
+
+func escapePointer(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
+
+// WriteMarkdown writes docs as a Markdown table.
+func WriteMarkdown(w io.Writer, docs []FieldDoc) error {
+	if _, err := io.WriteString(w, "| Path | Type | Kind | States | Len | Values | Description |\n"+
+		"| --- | --- | --- | --- | --- | --- | --- |\n"); err != nil {
+		return err
+	}
+	for _, d := range docs {
+		kind := d.Kind
+		if d.Collection != "" {
+			kind = d.Collection + " of " + kind
+		}
+		_, err := fmt.Fprintf(
+			w,
+			"| %s | %s | %s | %s | %s | %s | %s |\n",
+			markdownCell(d.Path, true),
+			markdownCell(d.Type, true),
+			markdownCell(kind, false),
+			markdownCell(strings.Join(d.States, ", "), false),
+			markdownCell(d.Len, true),
+			markdownCell(strings.Join(d.Values, ", "), true),
+			markdownCell(d.Description, false),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markdownCell(s string, code bool) string {
+	if s == "" {
+		return ""
+	}
+	s = strings.ReplaceAll(s, "|", `\|`)
+	if code {
+		return "`" + s + "`"
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+package validate_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ngicks/und"
+	"github.com/ngicks/und/option"
+	"github.com/ngicks/und/sliceund/elastic"
+	"github.com/ngicks/und/undtag"
+	"github.com/ngicks/und/validate"
+	"gotest.tools/v3/assert"
+)
+
+type (
+	documented struct {
+		ID       option.Option[string]        `json:"id" und:"create:und;update:required"`
+		Name     und.Und[string]              `json:"name" und:"def,null,und"`
+		Children elastic.Elastic[documentedC] `json:"children" und:"len=1..3,values:nonnull"`
+		Plain    string                       `json:"plain"`
+		Self     *documented                  `json:"self"`
+	}
+	documentedC struct {
+		A map[string]option.Option[int] `json:"a" und:"required"`
+	}
+)
+
+func TestDocument(t *testing.T) {
+	docs, err := validate.Document(reflect.TypeFor[*documented]())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []validate.FieldDoc{
+		{Path: "/id", Type: "option.Option[string]", Kind: "option"},
+		{
+			Path: "/name", Type: "und.Und[string]", Kind: "und",
+			Tag:         "def,null,und",
+			States:      []string{"defined", "null", "undefined"},
+			Description: "must be defined or null or undefined",
+		},
+		{
+			Path: "/children", Type: "elastic.Elastic[github.com/ngicks/und/validate_test.documentedC]", Kind: "elastic",
+			Tag:         "def,len=1..3,values:nonnull",
+			States:      []string{"defined"},
+			Len:         "len=1..3",
+			Values:      []string{"values:nonnull"},
+			Description: "must be defined, and defined or must have length of between 1 and 3, and must not contain null",
+		},
+		{
+			Path: "/children/*/a", Type: "map[string]option.Option[int]", Kind: "option", Collection: "map",
+			Tag:         "required",
+			States:      []string{"defined"},
+			Description: "is required",
+		},
+		{Path: "/plain", Type: "string", Kind: "plain"},
+		{Path: "/self", Type: "*validate_test.documented", Kind: "plain"},
+	}, docs)
+
+	docs, err = validate.DocumentScope(reflect.TypeFor[documented](), "update")
+	assert.NilError(t, err)
+	assert.Equal(t, "required", docs[0].Tag)
+
+	var b strings.Builder
+	assert.NilError(t, validate.WriteMarkdown(&b, docs[:2]))
+	assert.Equal(
+		t,
+		"| Path | Type | Kind | States | Len | Values | Description |\n"+
+			"| --- | --- | --- | --- | --- | --- | --- |\n"+
+			"| `/id` | `option.Option[string]` | option | defined |  |  | is required |\n"+
+			"| `/name` | `und.Und[string]` | und | defined, null, undefined |  |  | must be defined or null or undefined |\n",
+		b.String(),
+	)
+
+	_, err = validate.Document(reflect.TypeFor[invalidMalformedLen1]())
+	assert.ErrorIs(t, err, undtag.ErrMalformedLen)
+	_, err = validate.Document(reflect.TypeFor[int]())
+	assert.ErrorIs(t, err, validate.ErrNotStruct)
+}
@@ -0,0 +1,206 @@
+package validate
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/ngicks/und/undtag"
+)
+
+// Code is a stable, machine-readable identifier of a kind of a validation failure.
+//
+// Violations of constraints registered by undtag.RegisterConstraint report their names as codes.
+type Code string
+
+const (
+	// CodeUnknown is reported by errors whose cause is not known to this package,
+	// e.g. errors created by [NewValidationError] or returned from UndValidate methods of user types.
+	CodeUnknown Code = "unknown"
+	// CodeState is reported when the state of a value, e.g. defined, null or undefined, is not allowed.
+	CodeState Code = "state"
+	// CodeLen is reported when the length of an elastic value violates the len option.
+	CodeLen Code = "len"
+	// CodeValues is reported when values of an elastic value violates the values option.
+	CodeValues Code = "values"
+	// CodeInvalidTag is reported when the `und` struct tag is malformed or placed on a field which can not be validated.
+	CodeInvalidTag Code = "invalid_tag"
+	// CodeGroup is reported at each member of a group when the group option is violated.
+	CodeGroup Code = "group"
+	// CodeRequires is reported at the required field when the requires option is violated.
+	CodeRequires Code = "requires"
+	// CodeCycle is reported when a cycle of pointers is detected. See [OnCycle].
+	CodeCycle Code = "cycle"
+	// CodeMaxDepth is reported when the input is nested too deep. See [MaxDepth].
+	CodeMaxDepth Code = "max_depth"
+	// CodeStruct is reported by errors returned from UndValidateStruct methods. See [StructValidator].
+	CodeStruct Code = "struct"
+	// CodeSyntax is reported by [UnmarshalJSON] and [Decoder] when the input is not a valid JSON.
+	CodeSyntax Code = "syntax"
+	// CodeType is reported by [UnmarshalJSON] and [Decoder] when a JSON value is not appropriate for the Go type.
+	CodeType Code = "type"
+	// CodeDecode is reported by [UnmarshalJSON] and [Decoder] when decoding failed for other reasons,
+	// e.g. an UnmarshalJSON method returned an error.
+	CodeDecode Code = "decode"
+)
+
+// newTagError returns an error for a malformed or misplaced `und` struct tag on the field name.
+func newTagError(err error, name string) error {
+	if pErr, ok := err.(*undtag.ParseError); ok {
+		err = tagError{pErr.WithField(name)}
+	}
+	return &ValidationError{
+		fieldChain: []fieldSelector{{fieldSelectorTypeDot, name}},
+		err:        err,
+		code:       CodeInvalidTag,
+	}
+}
+
+// tagError is a *undtag.ParseError whose Field is set but not printed,
+// since *ValidationError prints the path to the field.
+type tagError struct {
+	err *undtag.ParseError
+}
+
+func (e tagError) Error() string {
+	return e.err.WithField("").Error()
+}
+
+func (e tagError) Unwrap() error {
+	return e.err
+}
+
+// violatedCode returns the code for the first constraint of opt which v violates.
+func violatedCode(opt undtag.UndOpt, v any) Code {
+	e, ok := v.(ElasticLike)
+	if !ok {
+		return CodeState
+	}
+	if states, ok := opt.States().Get(); ok && !states.Valid(e) {
+		return CodeState
+	}
+	if l, ok := opt.Len().Get(); ok && !l.Valid(e) {
+		return CodeLen
+	}
+	if values, ok := opt.Values().Get(); ok && !values.Valid(e) {
+		return CodeValues
+	}
+	return CodeState
+}
+
+// Path returns selectors from the root to the invalid value: field names, slice indices and map keys.
+func (e *ValidationError) Path() []string {
+	path := make([]string, 0, len(e.fieldChain))
+	for _, f := range slices.Backward(e.fieldChain) {
+		path = append(path, f.selector)
+	}
+	return path
+}
+
+// Code returns the kind of the failure.
+// It returns [CodeUnknown] if the error is not created by this package.
+func (e *ValidationError) Code() Code {
+	if e.code == "" {
+		return CodeUnknown
+	}
+	return e.code
+}
+
+// Constraint returns the violated constraint parsed from the `und` struct tag.
+// ok is false if e is not a violation of the constraint, e.g. the tag itself is malformed.
+func (e *ValidationError) Constraint() (opt undtag.UndOpt, ok bool) {
+	return e.opt.Get()
+}
+
+// State returns the observed state of the invalid value as reported by [ReportState].
+// It is empty if e is not a violation of the constraint.
+func (e *ValidationError) State() string {
+	return e.state
+}
+
+// Message returns the error message without the path.
+func (e *ValidationError) Message() string {
+	return e.err.Error()
+}
+
+type validationErrorJSON struct {
+	Path       []string        `json:"path"`
+	Pointer    string          `json:"pointer"`
+	Code       Code            `json:"code"`
+	Constraint *constraintJSON `json:"constraint,omitempty"`
+	State      string          `json:"state,omitempty"`
+	Message    string          `json:"message"`
+}
+
+type constraintJSON struct {
+	// States lists allowed states: "defined", "null" and "undefined".
+	States []string    `json:"states,omitempty"`
+	Len    *lenJSON    `json:"len,omitempty"`
+	Values *valuesJSON `json:"values,omitempty"`
+}
+
+type lenJSON struct {
+	Op  string `json:"op"`
+	Len int    `json:"len"`
+	Max *int   `json:"max,omitempty"` // only for ranges.
+}
+
+type valuesJSON struct {
+	Nonnull bool `json:"nonnull,omitempty"`
+	Unique  bool `json:"unique,omitempty"`
+	Nonzero bool `json:"nonzero,omitempty"`
+	Sorted  bool `json:"sorted,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// e is encoded as an object like below.
+// constraint and state are omitted if e is not a violation of the constraint.
+//
+//	{
+//		"path": ["a", "0", "b"],
+//		"pointer": "/a/0/b",
+//		"code": "len",
+//		"constraint": {"states": ["defined"], "len": {"op": ">=", "len": 1}},
+//		"state": "defined, len=0, has null=false",
+//		"message": "input defined or must have length of >= 1"
+//	}
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	enc := validationErrorJSON{
+		Path:    e.Path(),
+		Pointer: e.Pointer(),
+		Code:    e.Code(),
+		State:   e.state,
+		Message: e.Message(),
+	}
+	if opt, ok := e.opt.Get(); ok {
+		var c constraintJSON
+		if states, ok := opt.States().Get(); ok {
+			c.States = []string{}
+			if states.Def {
+				c.States = append(c.States, "defined")
+			}
+			if states.Null {
+				c.States = append(c.States, "null")
+			}
+			if states.Und {
+				c.States = append(c.States, "undefined")
+			}
+		}
+		if l, ok := opt.Len().Get(); ok {
+			c.Len = &lenJSON{Op: l.Op.String(), Len: l.Len}
+			if l.Op == undtag.LenOpRange {
+				c.Len.Max = &l.Max
+			}
+		}
+		if values, ok := opt.Values().Get(); ok {
+			c.Values = &valuesJSON{
+				Nonnull: values.Nonnull,
+				Unique:  values.Unique,
+				Nonzero: values.Nonzero,
+				Sorted:  values.Sorted,
+			}
+		}
+		enc.Constraint = &c
+	}
+	return json.Marshal(enc)
+}
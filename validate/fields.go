@@ -0,0 +1,42 @@
+package validate
+
+import (
+	"reflect"
+
+	"github.com/ngicks/und/internal/structfield"
+)
+
+// structField is a field of a struct as the encoder sees it.
+type structField struct {
+	sf   reflect.StructField
+	name string
+	// index is the index sequence for reflect.Value.FieldByIndex.
+	// len(index) > 1 if the field is promoted from embedded structs.
+	index  []int
+	tagged bool
+}
+
+// visibleFields lists fields of rt, a struct type, following the rules of encoding/json.
+// See [structfield.Visible] for details.
+func visibleFields(rt reflect.Type, nameTag string) []structField {
+	var fields []structField
+	for _, f := range structfield.Visible(rt, nameTag) {
+		fields = append(fields, structField{sf: f.StructField, name: f.Name, index: f.Index, tagged: f.Tagged})
+	}
+	return fields
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but returns false
+// instead of panicking when it steps into a nil embedded pointer.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
@@ -0,0 +1,174 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ngicks/und/internal/option"
+	"github.com/ngicks/und/undtag"
+)
+
+// groupMember is a field having group or requires options.
+type groupMember struct {
+	field structField
+	opt   undtag.UndOpt
+}
+
+// makeGroupValidators makes struct level validators for group and requires options of fields.
+func makeGroupValidators(fields []structField, cfg tagConfig) ([]func(rv reflect.Value, w *walker) error, error) {
+	var (
+		members    []groupMember
+		groupNames []string
+		groups     = map[string][]groupMember{}
+		kinds      = map[string]undtag.GroupKind{}
+	)
+	for _, f := range fields {
+		if cfg.undTag(f.sf) == "" {
+			continue
+		}
+		// Malformed tags are reported by field validators, or the field is not validated at all.
+		opt, err := cfg.parseUndTag(f.sf)
+		if err != nil || len(opt.Groups()) == 0 && len(opt.Requires()) == 0 {
+			continue
+		}
+		m := groupMember{field: f, opt: opt}
+		members = append(members, m)
+		for _, g := range opt.Groups() {
+			kind, ok := kinds[g.Name]
+			if !ok {
+				kinds[g.Name] = g.Kind
+				groupNames = append(groupNames, g.Name)
+			} else if kind != g.Kind {
+				return nil, newTagError(
+					fmt.Errorf("%w: group %q has conflicting kinds %s and %s", undtag.ErrMalformedGroup, g.Name, kind, g.Kind),
+					f.name,
+				)
+			}
+			groups[g.Name] = append(groups[g.Name], m)
+		}
+	}
+
+	var validators []func(rv reflect.Value, w *walker) error
+	for _, name := range groupNames {
+		validators = append(validators, makeGroupValidator(name, kinds[name], groups[name]))
+	}
+	for _, m := range members {
+		for _, required := range m.opt.Requires() {
+			target, ok := lookupField(fields, required)
+			if !ok {
+				return nil, newTagError(
+					fmt.Errorf("%w: requires unknown field %q", undtag.ErrMalformedGroup, required),
+					m.field.name,
+				)
+			}
+			validators = append(validators, makeRequiresValidator(m, target))
+		}
+	}
+	return validators, nil
+}
+
+func lookupField(fields []structField, name string) (structField, bool) {
+	for _, f := range fields {
+		if f.sf.Name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
+
+func makeGroupValidator(name string, kind undtag.GroupKind, members []groupMember) func(rv reflect.Value, w *walker) error {
+	names := make([]string, len(members))
+	for i, m := range members {
+		names[i] = m.field.name
+	}
+	desc := fmt.Sprintf("%s group %s (%s) must be defined", kind.Describe(), name, strings.Join(names, ", "))
+	return func(rv reflect.Value, w *walker) error {
+		var defined int
+		for _, m := range members {
+			if fv, ok := fieldByIndex(rv, m.field.index); ok && isDefinedValue(fv) {
+				defined++
+			}
+		}
+		if kind.Valid(defined, len(members)) {
+			return nil
+		}
+		// reports the violation at each member.
+		for _, m := range members {
+			fv, _ := fieldByIndex(rv, m.field.index)
+			w.push(fieldSelector{fieldSelectorTypeDot, m.field.name})
+			err := w.report(&ValidationError{
+				err:   fmt.Errorf("input is a member of %s", desc),
+				code:  CodeGroup,
+				opt:   option.Some(m.opt),
+				state: memberState(fv),
+			})
+			w.pop()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func makeRequiresValidator(m groupMember, target structField) func(rv reflect.Value, w *walker) error {
+	return func(rv reflect.Value, w *walker) error {
+		fv, ok := fieldByIndex(rv, m.field.index)
+		if !ok || !isDefinedValue(fv) {
+			return nil
+		}
+		tv, ok := fieldByIndex(rv, target.index)
+		if ok && isDefinedValue(tv) {
+			return nil
+		}
+		// reports at the missing field.
+		w.push(fieldSelector{fieldSelectorTypeDot, target.name})
+		defer w.pop()
+		return w.report(&ValidationError{
+			err:   fmt.Errorf("input must be defined since %s is defined", m.field.name),
+			code:  CodeRequires,
+			opt:   option.Some(m.opt),
+			state: memberState(tv),
+		})
+	}
+}
+
+// isDefinedValue reports whether fv, a field value, is defined.
+// nil pointers, slices and maps, undefined or null und types, and none option types are not defined.
+func isDefinedValue(fv reflect.Value) bool {
+	if !fv.IsValid() {
+		return false
+	}
+	switch fv.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		if fv.IsNil() {
+			return false
+		}
+	}
+	if fv.Kind() == reflect.Pointer {
+		fv = fv.Elem()
+	}
+	if fv.CanInterface() {
+		return isFilled(fv.Interface()) || !isContainer(fv.Type())
+	}
+	return true
+}
+
+func memberState(fv reflect.Value) string {
+	if !fv.IsValid() {
+		return "undefined"
+	}
+	if fv.Kind() == reflect.Pointer && !fv.IsNil() && isContainer(fv.Type().Elem()) {
+		fv = fv.Elem()
+	}
+	if fv.CanInterface() && isContainer(fv.Type()) {
+		return ReportState(fv.Interface())
+	}
+	return ReportState(plainValue{some: isDefinedValue(fv)})
+}
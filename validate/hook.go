@@ -0,0 +1,71 @@
+package validate
+
+import "reflect"
+
+// StructValidator wraps the UndValidateStruct method.
+//
+// UndValidateStruct is implemented on user-defined struct types to check invariants of the type
+// which `und` struct tags can not express, e.g. "if Status is null then Reason must be defined".
+// UndValidate calls it on every struct it visits after checking its fields,
+// including structs nested in fields, pointers, slices, maps and data container types.
+//
+// A returned error is reported as a *ValidationError at the path of the struct.
+// If the error is already a *ValidationError, e.g. one built with [NewValidationError] and [AppendValidationErrorDot],
+// its path is appended to the path of the struct so that the error can point to a field.
+// Errors joined by errors.Join are reported separately.
+type StructValidator interface {
+	UndValidateStruct() error
+}
+
+var structValidatorTy = reflect.TypeFor[StructValidator]()
+
+// implementsStructValidator reports whether rt or a pointer to rt implements StructValidator.
+func implementsStructValidator(rt reflect.Type) bool {
+	return rt.Implements(structValidatorTy) || reflect.PointerTo(rt).Implements(structValidatorTy)
+}
+
+// callStructValidator calls UndValidateStruct on rv, a struct value, and reports returned errors.
+func callStructValidator(rv reflect.Value, w *walker) error {
+	if !rv.CanInterface() {
+		// reached through unexported fields.
+		return nil
+	}
+	var sv StructValidator
+	switch {
+	case rv.Type().Implements(structValidatorTy):
+		sv = rv.Interface().(StructValidator)
+	case rv.CanAddr():
+		sv = rv.Addr().Interface().(StructValidator)
+	default:
+		// the method has a pointer receiver; call it on a copy.
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		sv = p.Interface().(StructValidator)
+	}
+	err := sv.UndValidateStruct()
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			if err := reportStructError(err, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return reportStructError(err, w)
+}
+
+func reportStructError(err error, w *walker) error {
+	vErr, ok := err.(*ValidationError)
+	if !ok {
+		return w.report(&ValidationError{err: err, code: CodeStruct})
+	}
+	if vErr.code == "" {
+		cloned := *vErr
+		cloned.code = CodeStruct
+		vErr = &cloned
+	}
+	return w.report(vErr)
+}
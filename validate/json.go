@@ -0,0 +1,411 @@
+package validate
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ngicks/und/internal/structfield"
+)
+
+// UnmarshalJSON decodes data into v by encoding/json and validates v by [UndValidate], collecting all violations.
+//
+// Decode errors are reported as *ValidationError as well, so that they have the same path format as violations:
+// syntax errors are reported at the root with [CodeSyntax],
+// type mismatches at the path of the mismatched value with [CodeType],
+// and other errors, e.g. ones returned from UnmarshalJSON methods, at the path of the value with [CodeDecode].
+// The returned error joins all of them; use [Errors] to retrieve them.
+//
+// After errors other than syntax errors, v is still validated as far as encoding/json has decoded it,
+// while violations at and under the path of the decode error are omitted since they are consequences of the error.
+// Whether encoding/json keeps decoding after an error depends on the error and the version of encoding/json.
+//
+// Before decoding, UnmarshalJSON reads data along with the type of v and rejects elastic values
+// having more elements than upper bounds of len options, or having null elements while tagged values:nonnull,
+// so that oversized inputs are never decoded into elastic types.
+// Reading stops at the first offending element, and then only that violation is returned with [CodeLen] or [CodeValues].
+// Other options, including lower bounds of len options, are checked after decoding.
+//
+// opts are passed to UndValidate after [CollectAll].
+// Paths of decode errors are built from names in `json` struct tag, regardless of [FieldNameTag].
+// Validation is skipped if v does not point to a struct.
+func UnmarshalJSON(data []byte, v any, opts ...ValidateOption) error {
+	if err := checkValueLimits(data, v, opts); err != nil {
+		return err
+	}
+	var d jsonDecoder
+	return d.decodeAndValidate(json.Unmarshal(data, v), data, v, opts)
+}
+
+// Decoder is a wrapper of json.Decoder which validates decoded values by [UndValidate].
+type Decoder struct {
+	dec  *json.Decoder
+	d    jsonDecoder
+	opts []ValidateOption
+}
+
+// NewDecoder returns a new Decoder which reads from r.
+// opts are passed to UndValidate after [CollectAll].
+func NewDecoder(r io.Reader, opts ...ValidateOption) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r), opts: opts}
+}
+
+// Decode reads the next JSON value from its input, stores it in v, and validates v.
+// Errors, including violations of limits checked before decoding, are reported in the same way as [UnmarshalJSON].
+// io.EOF is returned as is.
+func (d *Decoder) Decode(v any) error {
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return d.d.decodeAndValidate(err, nil, v, d.opts)
+	}
+	if err := checkValueLimits(raw, v, d.opts); err != nil {
+		return err
+	}
+	return d.d.decodeAndValidate(d.d.decode(raw, v), raw, v, d.opts)
+}
+
+// DisallowUnknownFields is same as json.Decoder.DisallowUnknownFields.
+// Unknown fields are reported at their paths with [CodeDecode].
+func (d *Decoder) DisallowUnknownFields() {
+	d.d.disallowUnknownFields = true
+}
+
+// UseNumber is same as json.Decoder.UseNumber.
+func (d *Decoder) UseNumber() {
+	d.d.useNumber = true
+}
+
+// More is same as json.Decoder.More.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// Marshal validates v by [UndValidate], collecting all violations, and encodes v by encoding/json only if it is valid.
+// opts are passed to UndValidate after [CollectAll].
+//
+// Validation is skipped if v is not a struct nor a pointer to a struct.
+func Marshal(v any, opts ...ValidateOption) ([]byte, error) {
+	if err := validateDecoded(v, opts); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+type jsonDecoder struct {
+	disallowUnknownFields bool
+	useNumber             bool
+}
+
+func (d jsonDecoder) decode(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if d.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if d.useNumber {
+		dec.UseNumber()
+	}
+	return dec.Decode(v)
+}
+
+func (d jsonDecoder) decodeAndValidate(decodeErr error, data []byte, v any, opts []ValidateOption) error {
+	if decodeErr == nil {
+		return validateDecoded(v, opts)
+	}
+	var invalidErr *json.InvalidUnmarshalError
+	if errors.As(decodeErr, &invalidErr) {
+		// v is not a pointer; it is not an input error.
+		return decodeErr
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(decodeErr, &syntaxErr) || errors.Is(decodeErr, io.ErrUnexpectedEOF) || data == nil {
+		return &ValidationError{err: decodeErr, code: CodeSyntax}
+	}
+
+	rt := reflect.TypeOf(v)
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	var failures []decodeFailure
+	d.locate(data, rt, decodeErr, &failures)
+
+	var errs []error
+	for _, f := range failures {
+		vErr := &ValidationError{err: f.err, code: CodeDecode}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(f.err, &typeErr) {
+			vErr.code = CodeType
+			vErr.state = typeErr.Value
+		}
+		for _, sel := range f.path {
+			// fieldChain is in leaf-first order.
+			vErr.fieldChain = append([]fieldSelector{sel}, vErr.fieldChain...)
+		}
+		errs = append(errs, vErr)
+	}
+	for _, vErr := range Errors(validateDecoded(v, opts)) {
+		if slices.ContainsFunc(errs, func(err error) bool {
+			p, decoded := vErr.Pointer(), err.(*ValidationError).Pointer()
+			return p == decoded || strings.HasPrefix(p, decoded+"/")
+		}) {
+			continue
+		}
+		errs = append(errs, vErr)
+	}
+	return errors.Join(errs...)
+}
+
+func validateDecoded(v any, opts []ValidateOption) error {
+	if v == nil {
+		return nil
+	}
+	err := UndValidate(v, append([]ValidateOption{CollectAll()}, opts...)...)
+	if errors.Is(err, ErrNotStruct) {
+		return nil
+	}
+	return err
+}
+
+var (
+	unmarshalerTy     = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerTy = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// decodeFailure is a value which could not be decoded.
+type decodeFailure struct {
+	// path is in root-first order.
+	path []fieldSelector
+	err  error
+}
+
+// locate finds values in data which fail to be decoded into rt, and appends them to failures.
+// Decoding data into rt must fail with err.
+// If no part of data is found to be failing, data itself is reported at the root.
+//
+// encoding/json does not tell where errors occur if they are returned from UnmarshalJSON methods,
+// e.g. ones of data container types, and it reports only the first error.
+// locate parses data once and walks it along with rt, so that all failures are reported with their paths.
+// Only values which can not be walked into, e.g. numbers, strings and values of types implementing json.Unmarshaler,
+// are decoded separately, thus each part of data is decoded at most once.
+func (d jsonDecoder) locate(data []byte, rt reflect.Type, err error, failures *[]decodeFailure) {
+	root, parseErr := parseJSONValue(json.NewDecoder(bytes.NewReader(data)), data)
+	if parseErr != nil || !d.locateValue(root, rt, nil, failures) {
+		*failures = append(*failures, decodeFailure{err: err})
+	}
+}
+
+// locateValue reports failing parts of n, including n itself. found is false if no part is found.
+func (d jsonDecoder) locateValue(n *jsonValue, rt reflect.Type, path []fieldSelector, failures *[]decodeFailure) (found bool) {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if isContainer(rt) {
+		elem, isElastic := containerElem(rt)
+		switch {
+		case elem == nil:
+			return d.locateLeaf(n, rt, path, failures)
+		case n.null:
+			return false
+		case isElastic && n.kind == '[':
+			return d.locateElements(n, elem, path, failures)
+		}
+		return d.locateValue(n, elem, path, failures)
+	}
+	if n.null || rt.Implements(unmarshalerTy) || reflect.PointerTo(rt).Implements(unmarshalerTy) {
+		return d.locateLeaf(n, rt, path, failures)
+	}
+	switch rt.Kind() {
+	case reflect.Struct:
+		if n.kind != '{' {
+			return d.locateLeaf(n, rt, path, failures)
+		}
+		fields := visibleFields(rt, DefaultFieldNameTag)
+		for _, m := range n.members {
+			f, ok := matchField(fields, m.key)
+			if !ok {
+				if d.disallowUnknownFields {
+					found = true
+					*failures = append(*failures, decodeFailure{
+						path: append(slices.Clone(path), fieldSelector{fieldSelectorTypeDot, m.key}),
+						err:  fmt.Errorf("json: unknown field %q", m.key),
+					})
+				}
+				continue
+			}
+			fieldPath := append(path, fieldSelector{fieldSelectorTypeDot, f.name})
+			if _, opts, _ := strings.Cut(f.sf.Tag.Get(DefaultFieldNameTag), ","); structfield.HasOption(opts, "string") {
+				// The string option changes how the value is decoded; decode it as a field with the option.
+				if err := d.tryDecodeField(m.value.raw, f.sf); err != nil {
+					found = true
+					*failures = append(*failures, decodeFailure{path: slices.Clone(fieldPath), err: err})
+				}
+				continue
+			}
+			if d.locateValue(m.value, f.sf.Type, fieldPath, failures) {
+				found = true
+			}
+		}
+		return found
+	case reflect.Map:
+		if n.kind != '{' {
+			return d.locateLeaf(n, rt, path, failures)
+		}
+		keyTy := reflect.MapOf(rt.Key(), reflect.TypeFor[struct{}]())
+		for _, m := range n.members {
+			memberPath := append(path, fieldSelector{fieldSelectorTypeIndex, m.key})
+			if rt.Key().Kind() != reflect.String || reflect.PointerTo(rt.Key()).Implements(textUnmarshalerTy) {
+				key, _ := json.Marshal(m.key)
+				if err := d.tryDecode([]byte("{"+string(key)+":{}}"), keyTy); err != nil {
+					found = true
+					*failures = append(*failures, decodeFailure{path: slices.Clone(memberPath), err: err})
+					continue
+				}
+			}
+			if d.locateValue(m.value, rt.Elem(), memberPath, failures) {
+				found = true
+			}
+		}
+		return found
+	case reflect.Slice, reflect.Array:
+		if n.kind != '[' {
+			// including []byte encoded as a base64 string.
+			return d.locateLeaf(n, rt, path, failures)
+		}
+		return d.locateElements(n, rt.Elem(), path, failures)
+	}
+	return d.locateLeaf(n, rt, path, failures)
+}
+
+func (d jsonDecoder) locateElements(n *jsonValue, elem reflect.Type, path []fieldSelector, failures *[]decodeFailure) (found bool) {
+	for i, e := range n.elements {
+		if d.locateValue(e, elem, append(path, fieldSelector{fieldSelectorTypeIndex, strconv.Itoa(i)}), failures) {
+			found = true
+		}
+	}
+	return found
+}
+
+// locateLeaf decodes n into rt and reports the error, if any, at path.
+func (d jsonDecoder) locateLeaf(n *jsonValue, rt reflect.Type, path []fieldSelector, failures *[]decodeFailure) bool {
+	if err := d.tryDecode(n.raw, rt); err != nil {
+		*failures = append(*failures, decodeFailure{path: slices.Clone(path), err: err})
+		return true
+	}
+	return false
+}
+
+func (d jsonDecoder) tryDecode(data []byte, rt reflect.Type) error {
+	return d.decode(data, reflect.New(rt).Interface())
+}
+
+// tryDecodeField decodes data as the value of sf, keeping options in `json` struct tag of sf.
+func (d jsonDecoder) tryDecodeField(data []byte, sf reflect.StructField) error {
+	rt := reflect.StructOf([]reflect.StructField{{Name: "V", Type: sf.Type, Tag: sf.Tag}})
+	name, _ := json.Marshal(rt.Field(0).Name)
+	if n, _, _ := strings.Cut(sf.Tag.Get(DefaultFieldNameTag), ","); n != "" {
+		name, _ = json.Marshal(n)
+	}
+	return d.tryDecode(slices.Concat([]byte("{"), name, []byte(":"), data, []byte("}")), rt)
+}
+
+// jsonValue is a JSON value parsed by parseJSONValue.
+type jsonValue struct {
+	raw  json.RawMessage
+	kind byte // the first byte of raw.
+	null bool
+	// members of an object in the order of appearance.
+	members []jsonMember
+	// elements of an array.
+	elements []*jsonValue
+}
+
+type jsonMember struct {
+	key   string
+	value *jsonValue
+}
+
+// parseJSONValue reads the next value from dec, which reads data from its start.
+// Objects and arrays are parsed recursively.
+func parseJSONValue(dec *json.Decoder, data []byte) (*jsonValue, error) {
+	start := dec.InputOffset()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	// skip separators consumed along with the token.
+	for start < int64(len(data)) && strings.IndexByte(" \t\r\n,:", data[start]) >= 0 {
+		start++
+	}
+	v := &jsonValue{null: tok == nil}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			member, err := parseJSONValue(dec, data)
+			if err != nil {
+				return nil, err
+			}
+			v.members = append(v.members, jsonMember{key: key.(string), value: member})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	case json.Delim('['):
+		for dec.More() {
+			elem, err := parseJSONValue(dec, data)
+			if err != nil {
+				return nil, err
+			}
+			v.elements = append(v.elements, elem)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+	v.raw = data[start:dec.InputOffset()]
+	v.kind = v.raw[0]
+	return v, nil
+}
+
+// containerElem returns the type of values stored in rt, a data container type.
+func containerElem(rt reflect.Type) (elem reflect.Type, isElastic bool) {
+	if rt.Implements(elasticLike) {
+		m, ok := rt.MethodByName("Pointers")
+		if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 || m.Type.Out(0).Kind() != reflect.Slice {
+			return nil, true
+		}
+		return m.Type.Out(0).Elem(), true
+	}
+	m, ok := rt.MethodByName("Value")
+	if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 {
+		return nil, false
+	}
+	return m.Type.Out(0), false
+}
+
+// matchField finds the field for key as encoding/json does; exact match is preferred over case-insensitive match.
+func matchField(fields []structField, key string) (structField, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return structField{}, false
+}
@@ -0,0 +1,201 @@
+package validate_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ngicks/und"
+	"github.com/ngicks/und/option"
+	"github.com/ngicks/und/sliceund/elastic"
+	"github.com/ngicks/und/validate"
+	"gotest.tools/v3/assert"
+)
+
+type (
+	decoded struct {
+		Name  option.Option[string] `json:"name" und:"required"`
+		Age   und.Und[int]          `json:"age" und:"def,und"`
+		Items []decodedItem         `json:"items"`
+		decodedEmbedded
+	}
+	decodedItem struct {
+		ID option.Option[int] `json:"id" und:"required"`
+	}
+	decodedEmbedded struct {
+		Note option.Option[string] `json:"note" und:"def,und"`
+	}
+)
+
+func TestUnmarshalJSON(t *testing.T) {
+	var v decoded
+	assert.NilError(t, validate.UnmarshalJSON([]byte(`{"name":"foo","items":[{"id":1}]}`), &v))
+	assert.Equal(t, "foo", v.Name.Value())
+
+	v = decoded{}
+	err := validate.UnmarshalJSON([]byte(`{"age":null,"items":[{"id":1},{}]}`), &v)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/name:state", "/age:state", "/items/1/id:state"}, pointersAndCodes(err))
+
+	v = decoded{}
+	err = validate.UnmarshalJSON([]byte(`{"age":"ten","items":[{"id":1},{"id":"x"}],"note":1}`), &v)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(
+		t,
+		[]string{"/age:type", "/items/1/id:type", "/note:type", "/name:state"},
+		pointersAndCodes(err),
+	)
+	var typeErr *json.UnmarshalTypeError
+	assert.Assert(t, errors.As(err, &typeErr))
+
+	err = validate.UnmarshalJSON([]byte(`{"name":`), &v)
+	assert.DeepEqual(t, []string{":syntax"}, pointersAndCodes(err))
+	err = validate.UnmarshalJSON([]byte(`{"name":"foo",}`), &v)
+	assert.DeepEqual(t, []string{":syntax"}, pointersAndCodes(err))
+
+	var invalidErr *json.InvalidUnmarshalError
+	assert.Assert(t, errors.As(validate.UnmarshalJSON([]byte(`{}`), v), &invalidErr))
+
+	var nested struct {
+		Ela elastic.Elastic[decodedItem]    `json:"ela"`
+		Map map[string]und.Und[decodedItem] `json:"map"`
+	}
+	err = validate.UnmarshalJSON([]byte(`{"map":{"c":{},"a/b":{"id":"x"}},"ela":[{"id":1},{"id":true}]}`), &nested)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/map/a~1b/id:type", "/ela/1/id:type", "/map/c/id:state"}, pointersAndCodes(err))
+
+	var m map[string]int
+	assert.NilError(t, validate.UnmarshalJSON([]byte(`{"a":1}`), &m))
+}
+
+type decodedOptions struct {
+	N    int                        `json:"n,string"`
+	P    *int64                     `json:",string"`
+	M    option.Option[int]         `json:"m"`
+	Skip option.Option[int]         `json:"-"`
+	Keys map[int]option.Option[int] `json:"keys"`
+}
+
+func TestUnmarshalJSON_field_options(t *testing.T) {
+	var v decodedOptions
+	assert.NilError(t, validate.UnmarshalJSON([]byte(`{"n":"12","P":"-3","m":1,"-":"x","keys":{"1":2}}`), &v))
+	assert.Equal(t, 12, v.N)
+	assert.Equal(t, int64(-3), *v.P)
+	assert.Assert(t, v.Skip.IsNone())
+
+	v = decodedOptions{}
+	err := validate.UnmarshalJSON([]byte(`{"n":"12","P":"-3","m":"x","-":"x","keys":{"1":"y","z":1}}`), &v)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/m:type", "/keys/1:type", "/keys/z:type"}, pointersAndCodes(err))
+
+	v = decodedOptions{}
+	err = validate.UnmarshalJSON([]byte(`{"n":12,"P":"x"}`), &v)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/n", "/P"}, pointers(err))
+}
+
+func TestUnmarshalJSON_deep(t *testing.T) {
+	type node struct {
+		Next option.Option[*node] `json:"next"`
+		V    option.Option[int]   `json:"v"`
+	}
+	var b strings.Builder
+	const depth = 500
+	for range depth {
+		b.WriteString(`{"v":1,"next":`)
+	}
+	b.WriteString(`{"v":"x"}`)
+	for range depth {
+		b.WriteString(`}`)
+	}
+	var v node
+	err := validate.UnmarshalJSON([]byte(b.String()), &v, validate.MaxDepth(0))
+	vErrs := validate.Errors(err)
+	assert.Equal(t, 1, len(vErrs))
+	assert.Equal(t, strings.Repeat("/next", depth)+"/v", vErrs[0].Pointer())
+	assert.Equal(t, validate.CodeType, vErrs[0].Code())
+}
+
+type (
+	limited struct {
+		Tags   elastic.Elastic[string]  `json:"tags" und:"len<=2"`
+		Refs   elastic.Elastic[string]  `json:"refs" und:"values:nonnull"`
+		Groups []elastic.Elastic[int]   `json:"groups" und:"len<=1"`
+		Items  []limitedItem            `json:"items"`
+		Matrix elastic.Elastic[[]int]   `json:"matrix" und:"len==1"`
+		Free   elastic.Elastic[float64] `json:"free"`
+	}
+	limitedItem struct {
+		IDs elastic.Elastic[int] `json:"ids" und:"len<2"`
+	}
+)
+
+func TestUnmarshalJSON_limits(t *testing.T) {
+	for input, expected := range map[string][]string{
+		`{"tags":["a","b"],"refs":["a"],"groups":[[1],2],"items":[{"ids":1}],"matrix":[1,2],"free":[1,2,3]}`: nil,
+		`{"tags":["a","b","c"]}`:                       {"/tags:len"},
+		`{"TAGS":["a","b","c"]}`:                       {"/tags:len"},
+		`{"refs":["a",null]}`:                          {"/refs:values"},
+		`{"groups":[[1],[2,3]]}`:                       {"/groups/1:len"},
+		`{"items":[{"ids":[1]},{"ids":[1,2]}]}`:        {"/items/1/ids:len"},
+		`{"matrix":[[1],[2]]}`:                         {"/matrix:len"},
+		`{"free":[1,2,3],"tags":["a","b","c","d"`:      {"/tags:len"}, // reading stops before the syntax error.
+		`{"tags":["a","b"],"refs":[null],"free":null}`: {"/refs:values"},
+	} {
+		var v limited
+		err := validate.UnmarshalJSON([]byte(input), &v)
+		assert.DeepEqual(t, expected, pointersAndCodes(err))
+		if len(expected) > 0 {
+			// rejected before decoding.
+			assert.DeepEqual(t, limited{}, v)
+		}
+	}
+
+	var v limited
+	vErrs := validate.Errors(validate.UnmarshalJSON([]byte(`{"tags":["a","b","c"]}`), &v))
+	assert.Equal(t, 1, len(vErrs))
+	opt, ok := vErrs[0].Constraint()
+	assert.Assert(t, ok)
+	assert.Equal(t, "def,len<=2", opt.String())
+
+	// lower bounds are checked after decoding.
+	err := validate.UnmarshalJSON([]byte(`{"items":[{"ids":[]}]}`), &struct {
+		Items []struct {
+			IDs elastic.Elastic[int] `json:"ids" und:"len>=1"`
+		} `json:"items"`
+	}{})
+	assert.DeepEqual(t, []string{"/items/0/ids:len"}, pointersAndCodes(err))
+
+	dec := validate.NewDecoder(strings.NewReader(`{"tags":["a"],"matrix":[[1]]} {"tags":["a","b","c"]}`))
+	assert.NilError(t, dec.Decode(&v))
+	assert.DeepEqual(t, []string{"/tags:len"}, pointersAndCodes(dec.Decode(&v)))
+}
+
+func TestDecoder(t *testing.T) {
+	dec := validate.NewDecoder(strings.NewReader(`{"name":"foo"} {} {"name":"bar","extra":1}`), validate.MaxErrors(1))
+	dec.DisallowUnknownFields()
+
+	var v decoded
+	assert.NilError(t, dec.Decode(&v))
+	v = decoded{}
+	assert.DeepEqual(t, []string{"/name:state"}, pointersAndCodes(dec.Decode(&v)))
+	v = decoded{}
+	assert.DeepEqual(t, []string{"/extra:decode"}, pointersAndCodes(dec.Decode(&v)))
+	assert.Assert(t, !dec.More())
+	assert.Equal(t, io.EOF, dec.Decode(&v))
+}
+
+func TestMarshal(t *testing.T) {
+	bin, err := validate.Marshal(decoded{Name: option.Some("foo")})
+	assert.NilError(t, err)
+	assert.Equal(t, `{"name":"foo","age":null,"items":null,"note":null}`, string(bin))
+
+	_, err = validate.Marshal(&decoded{Age: und.Null[int]()})
+	assert.DeepEqual(t, []string{"/name:state", "/age:state"}, pointersAndCodes(err))
+
+	bin, err = validate.Marshal([]int{1})
+	assert.NilError(t, err)
+	assert.Equal(t, `[1]`, string(bin))
+}
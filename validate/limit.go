@@ -0,0 +1,324 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ngicks/und/internal/option"
+	"github.com/ngicks/und/internal/structfield"
+	"github.com/ngicks/und/undtag"
+)
+
+// elasticLimit is a limit placed on elastic values by len and values options.
+// Unlike validation after decoding, it is enforced while reading the input,
+// so that oversized elastic values are rejected before they are decoded.
+type elasticLimit struct {
+	opt undtag.UndOpt
+	// max is the maximum number of elements, valid only if hasMax is true.
+	// It is negative if no element is allowed.
+	max     int
+	hasMax  bool
+	nonnull bool
+}
+
+// newElasticLimit derives a limit from opt. ok is false if opt does not limit elastic values while decoding.
+func newElasticLimit(opt undtag.UndOpt) (limit elasticLimit, ok bool) {
+	limit.opt = opt
+	if l, ok := opt.Len().Get(); ok {
+		limit.max, limit.hasMax = l.UpperBound()
+	}
+	if values, ok := opt.Values().Get(); ok {
+		limit.nonnull = values.Nonnull
+	}
+	return limit, limit.hasMax || limit.nonnull
+}
+
+// fieldLimit returns the limit placed on f by its `und` struct tag.
+// It is none if f is not an elastic type nor a collection of elastic types,
+// or the tag is malformed, which is reported by validation instead.
+func fieldLimit(f structField, cfg tagConfig) option.Option[elasticLimit] {
+	if cfg.undTag(f.sf) == "" {
+		return option.None[elasticLimit]()
+	}
+	base := derefType(f.sf.Type)
+	if !base.Implements(elasticLike) && (collectionKind(base) == "" || !derefType(base.Elem()).Implements(elasticLike)) {
+		return option.None[elasticLimit]()
+	}
+	opt, err := cfg.parseUndTag(f.sf)
+	if err != nil {
+		return option.None[elasticLimit]()
+	}
+	limit, ok := newElasticLimit(opt)
+	if !ok {
+		return option.None[elasticLimit]()
+	}
+	return option.Some(limit)
+}
+
+// jsonFields returns fields of rt, a struct type, as encoding/json decodes them.
+// Fields with the string option are excluded since they are not walked into.
+func jsonFields(rt reflect.Type) []structField {
+	return slices.DeleteFunc(visibleFields(rt, DefaultFieldNameTag), func(f structField) bool {
+		_, opts, _ := strings.Cut(f.sf.Tag.Get(DefaultFieldNameTag), ",")
+		return structfield.HasOption(opts, "string")
+	})
+}
+
+var limitedCache sync.Map
+
+// hasLimits reports whether values of rt might contain elastic values limited by `und` struct tags.
+func hasLimits(rt reflect.Type, cfg tagConfig) bool {
+	key := cacheKey{rt, cfg}
+	v, ok := limitedCache.Load(key)
+	if !ok {
+		v, _ = limitedCache.LoadOrStore(key, hasLimitsVisited(rt, cfg, map[reflect.Type]bool{}))
+	}
+	return v.(bool)
+}
+
+func hasLimitsVisited(rt reflect.Type, cfg tagConfig, visited map[reflect.Type]bool) bool {
+	rt = derefType(rt)
+	if visited[rt] {
+		return false
+	}
+	visited[rt] = true
+	if isContainer(rt) {
+		elem, _ := containerElem(rt)
+		return elem != nil && hasLimitsVisited(elem, cfg, visited)
+	}
+	switch rt.Kind() {
+	case reflect.Struct:
+		for _, f := range jsonFields(rt) {
+			if fieldLimit(f, cfg).IsSome() || hasLimitsVisited(f.sf.Type, cfg, visited) {
+				return true
+			}
+		}
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return hasLimitsVisited(rt.Elem(), cfg, visited)
+	}
+	return false
+}
+
+// checkValueLimits is checkLimits for data decoded into v.
+func checkValueLimits(data []byte, v any, opts []ValidateOption) error {
+	rt := reflect.TypeOf(v)
+	if rt == nil || rt.Kind() != reflect.Pointer {
+		// decoding reports it.
+		return nil
+	}
+	return checkLimits(data, rt.Elem(), newWalker(opts).opts.tagConfig())
+}
+
+// checkLimits reads data along with rt and returns a *ValidationError for the first elastic value
+// exceeding the upper bound of its len option, or having a null element while tagged values:nonnull.
+// Reading stops at the offending element.
+//
+// It returns nil if data is not a valid JSON; decoding reports it instead.
+func checkLimits(data []byte, rt reflect.Type, cfg tagConfig) error {
+	if !hasLimits(rt, cfg) {
+		return nil
+	}
+	s := limitScanner{dec: json.NewDecoder(bytes.NewReader(data)), cfg: cfg}
+	vErr, _ := s.value(rt, option.None[elasticLimit]())
+	if vErr == nil {
+		return nil
+	}
+	return vErr
+}
+
+// limitScanner walks tokens of a JSON input along with a Go type.
+type limitScanner struct {
+	dec *json.Decoder
+	cfg tagConfig
+	// path is the current path from the root, in root-first order.
+	path []fieldSelector
+}
+
+// value reads the next value as rt. limit is the limit placed on rt or on its elements.
+// A non-nil vErr is a violation of a limit, and err is an error reading the input.
+func (s *limitScanner) value(rt reflect.Type, limit option.Option[elasticLimit]) (vErr *ValidationError, err error) {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	return s.valueToken(tok, rt, limit)
+}
+
+// valueToken is like value but tok, the first token of the value, is already read.
+func (s *limitScanner) valueToken(tok json.Token, rt reflect.Type, limit option.Option[elasticLimit]) (*ValidationError, error) {
+	if _, ok := tok.(json.Delim); !ok {
+		// null or scalars.
+		return nil, nil
+	}
+	rt = derefType(rt)
+	if isContainer(rt) {
+		elem, isElastic := containerElem(rt)
+		switch {
+		case elem == nil:
+			return nil, s.skip(tok)
+		case isElastic:
+			return s.elastic(tok, elem, limit)
+		}
+		return s.valueToken(tok, elem, option.None[elasticLimit]())
+	}
+	if rt.Implements(unmarshalerTy) || reflect.PointerTo(rt).Implements(unmarshalerTy) {
+		return nil, s.skip(tok)
+	}
+
+	// the limit on a collection is applied to its elements.
+	elemLimit := option.None[elasticLimit]()
+	if collectionKind(rt) != "" && derefType(rt.Elem()).Implements(elasticLike) {
+		elemLimit = limit
+	}
+	switch {
+	case rt.Kind() == reflect.Struct && tok == json.Delim('{'):
+		fields := jsonFields(rt)
+		return s.members(func(key string) (reflect.Type, option.Option[elasticLimit], fieldSelector, bool) {
+			f, ok := matchField(fields, key)
+			if !ok {
+				return nil, option.None[elasticLimit](), fieldSelector{}, false
+			}
+			return f.sf.Type, fieldLimit(f, s.cfg), fieldSelector{fieldSelectorTypeDot, f.name}, true
+		})
+	case rt.Kind() == reflect.Map && tok == json.Delim('{'):
+		return s.members(func(key string) (reflect.Type, option.Option[elasticLimit], fieldSelector, bool) {
+			return rt.Elem(), elemLimit, fieldSelector{fieldSelectorTypeIndex, key}, true
+		})
+	case (rt.Kind() == reflect.Slice || rt.Kind() == reflect.Array) && tok == json.Delim('['):
+		for i := 0; s.dec.More(); i++ {
+			s.path = append(s.path, fieldSelector{fieldSelectorTypeIndex, strconv.Itoa(i)})
+			vErr, err := s.value(rt.Elem(), elemLimit)
+			s.path = s.path[:len(s.path)-1]
+			if vErr != nil || err != nil {
+				return vErr, err
+			}
+		}
+		_, err := s.dec.Token()
+		return nil, err
+	}
+	return nil, s.skip(tok)
+}
+
+// members reads members of an object whose '{' is already read.
+// lookup returns how the value of key is read, or false if the value should be skipped.
+func (s *limitScanner) members(
+	lookup func(key string) (rt reflect.Type, limit option.Option[elasticLimit], sel fieldSelector, ok bool),
+) (*ValidationError, error) {
+	for s.dec.More() {
+		key, err := s.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		rt, limit, sel, ok := lookup(key.(string))
+		if !ok {
+			tok, err := s.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if err := s.skip(tok); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		s.path = append(s.path, sel)
+		vErr, err := s.value(rt, limit)
+		s.path = s.path[:len(s.path)-1]
+		if vErr != nil || err != nil {
+			return vErr, err
+		}
+	}
+	_, err := s.dec.Token()
+	return nil, err
+}
+
+// elastic reads an elastic value whose first token, tok, is already read. elem is the type of its elements.
+func (s *limitScanner) elastic(tok json.Token, elem reflect.Type, limit option.Option[elasticLimit]) (*ValidationError, error) {
+	if tok != json.Delim('[') {
+		// a single value.
+		if l, ok := limit.Get(); ok && l.hasMax && l.max < 1 {
+			return s.violation(l, CodeLen, "defined, len=1"), nil
+		}
+		return s.valueToken(tok, elem, option.None[elasticLimit]())
+	}
+	for i := 0; s.dec.More(); i++ {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 && isSingleArray(elem, tok) {
+			// T is a slice and the array is a single T. See UnmarshalJSON of elastic types.
+			if l, ok := limit.Get(); ok && l.hasMax && l.max < 1 {
+				return s.violation(l, CodeLen, "defined, len=1"), nil
+			}
+			if err := s.skip(tok); err != nil {
+				return nil, err
+			}
+			return nil, s.skip(json.Delim('['))
+		}
+		if l, ok := limit.Get(); ok {
+			if l.hasMax && i >= l.max {
+				return s.violation(l, CodeLen, fmt.Sprintf("defined, len>%d", max(l.max, 0))), nil
+			}
+			if l.nonnull && tok == nil {
+				return s.violation(l, CodeValues, "defined, has null=true"), nil
+			}
+		}
+		s.path = append(s.path, fieldSelector{fieldSelectorTypeIndex, strconv.Itoa(i)})
+		vErr, err := s.valueToken(tok, elem, option.None[elasticLimit]())
+		s.path = s.path[:len(s.path)-1]
+		if vErr != nil || err != nil {
+			return vErr, err
+		}
+	}
+	_, err := s.dec.Token()
+	return nil, err
+}
+
+// isSingleArray reports whether an array whose first element starts with tok is a single value of elem.
+func isSingleArray(elem reflect.Type, tok json.Token) bool {
+	elem = derefType(elem)
+	if elem.Kind() != reflect.Slice && elem.Kind() != reflect.Array || elem.Elem().Kind() == reflect.Uint8 {
+		// []byte is encoded as a string.
+		return false
+	}
+	return tok != nil && tok != json.Delim('[')
+}
+
+// skip skips the rest of the value whose first token, tok, is already read.
+func (s *limitScanner) skip(tok json.Token) error {
+	if tok != json.Delim('{') && tok != json.Delim('[') {
+		return nil
+	}
+	for depth := 1; depth > 0; {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return nil
+}
+
+func (s *limitScanner) violation(limit elasticLimit, code Code, state string) *ValidationError {
+	vErr := &ValidationError{
+		err:   fmt.Errorf("input %s", limit.opt.Describe()),
+		code:  code,
+		opt:   option.Some(limit.opt),
+		state: state,
+	}
+	for _, sel := range slices.Backward(s.path) {
+		vErr.fieldChain = append(vErr.fieldChain, sel)
+	}
+	return vErr
+}
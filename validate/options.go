@@ -0,0 +1,137 @@
+package validate
+
+import (
+	"slices"
+	"strings"
+)
+
+// ValidateOption configures behavior of [UndValidate].
+type ValidateOption func(o *validateOptions)
+
+type validateOptions struct {
+	collectAll  bool
+	maxErrors   int
+	nameTag     string
+	scope       string
+	scopes      []string
+	nilPolicy   NilPolicy
+	cyclePolicy CyclePolicy
+	maxDepth    int
+}
+
+// DefaultFieldNameTag is the struct tag key from which names of fields in paths of errors are read by default.
+const DefaultFieldNameTag = "json"
+
+// CollectAll makes UndValidate walk the entire value and return every violation,
+// instead of returning at the first one.
+//
+// Violations are joined by errors.Join, and each of them is a *ValidationError with its own path.
+// Use [Errors] to retrieve them.
+func CollectAll() ValidateOption {
+	return func(o *validateOptions) {
+		o.collectAll = true
+	}
+}
+
+// MaxErrors is like [CollectAll] but UndValidate stops walking
+// once it has collected n violations.
+// n <= 0 means no limit.
+func MaxErrors(n int) ValidateOption {
+	return func(o *validateOptions) {
+		o.collectAll = true
+		o.maxErrors = n
+	}
+}
+
+// FieldNameTag makes UndValidate and UndCheck read names of fields from the key struct tag instead of `json`,
+// e.g. `yaml` or `form` for non-JSON transports.
+//
+// As well as `json`, "-" skips the field and an embedded struct without name is inlined.
+// The inline option, e.g. `yaml:",inline"`, also inlines the field.
+func FieldNameTag(key string) ValidateOption {
+	return func(o *validateOptions) {
+		o.nameTag = key
+	}
+}
+
+// NilPolicy decides how a nil pointer to a data container type, e.g. *option.Option[T] or *und.Und[T], is validated.
+type NilPolicy int
+
+const (
+	// NilAsUndefined treats nil as undefined, or none for option types. This is the default.
+	NilAsUndefined NilPolicy = iota
+	// NilAsNull treats nil as null, or none for option types.
+	NilAsNull
+	// NilInvalid reports nil as a violation regardless of options in `und` struct tag.
+	NilInvalid
+)
+
+// NilPointer sets the policy for nil pointer fields, and nil pointer elements of slices, arrays and maps,
+// of data container types.
+func NilPointer(p NilPolicy) ValidateOption {
+	return func(o *validateOptions) {
+		o.nilPolicy = p
+	}
+}
+
+// CyclePolicy decides how pointers visited again are handled.
+//
+// UndValidate tracks pointers to structs by their addresses.
+// Values pointed by a pointer are validated only once, whether the pointer forms a cycle
+// (e.g. a back-reference to the parent) or is shared by multiple paths.
+type CyclePolicy int
+
+const (
+	// CycleSkip silently skips pointers already visited. This is the default.
+	CycleSkip CyclePolicy = iota
+	// CycleError reports an error wrapping [ErrCycle] for pointers forming a cycle.
+	// Shared pointers not forming a cycle are still skipped.
+	CycleError
+)
+
+// OnCycle sets the policy for pointers visited again.
+func OnCycle(p CyclePolicy) ValidateOption {
+	return func(o *validateOptions) {
+		o.cyclePolicy = p
+	}
+}
+
+// DefaultMaxDepth is the default limit of the depth of the path.
+// It is same as the maximum nesting depth allowed by encoding/json.
+const DefaultMaxDepth = 10000
+
+// MaxDepth limits the depth of the path, the number of fields, indices and keys from the root.
+// Values nested deeper than the limit are reported as an error wrapping [ErrMaxDepth] and not validated.
+// n <= 0 means no limit.
+func MaxDepth(n int) ValidateOption {
+	return func(o *validateOptions) {
+		o.maxDepth = n
+	}
+}
+
+func withScope(scope string) ValidateOption {
+	return func(o *validateOptions) {
+		o.scope = scope
+	}
+}
+
+// AllowedScopes makes UndValidate and UndCheck report scoped `und` struct tags having scopes other than names,
+// e.g. a misspelled scope "udpate" in `und:"create:required;udpate:def,und"`, as errors wrapping [undtag.ErrUnknownScope].
+//
+// Without AllowedScopes, any scope name is allowed.
+func AllowedScopes(names ...string) ValidateOption {
+	return func(o *validateOptions) {
+		o.scopes = append(o.scopes, names...)
+	}
+}
+
+func (o validateOptions) tagConfig() tagConfig {
+	cfg := tagConfig{nameTag: o.nameTag, scope: o.scope}
+	if len(o.scopes) > 0 {
+		scopes := slices.Clone(o.scopes)
+		slices.Sort(scopes)
+		// scope names never contain ",".
+		cfg.allowedScopes = "," + strings.Join(slices.Compact(scopes), ",") + ","
+	}
+	return cfg
+}
@@ -0,0 +1,264 @@
+// Package problem decodes and validates JSON request bodies,
+// reporting failures as RFC 9457 problem details.
+//
+// A request body is decoded into T by encoding/json and then validated by [validate.UndValidate],
+// collecting all violations.
+// On failure, an application/problem+json response is written, whose "errors" member lists
+// JSON pointers to invalid values and messages.
+//
+//	{
+//		"type": "about:blank",
+//		"title": "Unprocessable Entity",
+//		"status": 422,
+//		"detail": "request body has 1 invalid value(s)",
+//		"errors": [
+//			{"pointer": "#/name", "detail": "input is required", "code": "state"}
+//		]
+//	}
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/ngicks/und/validate"
+)
+
+// ContentType is the media type of problem details.
+const ContentType = "application/problem+json"
+
+// Problem is a problem details object defined in RFC 9457.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Errors is an extension member listing violations.
+	Errors []Error `json:"errors,omitempty"`
+}
+
+// Error is a single violation.
+type Error struct {
+	// Pointer is a JSON pointer (RFC 6901) to the invalid value in the request body.
+	Pointer string `json:"pointer"`
+	// Detail is a human-readable explanation.
+	Detail string `json:"detail"`
+	// Code is a machine-readable kind of the violation.
+	Code validate.Code `json:"code,omitempty"`
+}
+
+// DecodeError is returned from [Decode] when the request body could not be decoded.
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return "decoding request body: " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigError is returned from [Decode] when T can not be validated, e.g. T has a malformed `und` struct tag.
+// It is a fault of the server, not of the request.
+type ConfigError struct {
+	Err error
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid type configuration: " + e.Err.Error()
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// check checks T by [validate.UndCheck].
+func check[T any](opts []validate.ValidateOption) error {
+	if reflect.TypeFor[T]().Kind() == reflect.Interface {
+		// validation is skipped since the dynamic type is not known until decoding.
+		return nil
+	}
+	var v T
+	err := validate.UndCheck(v, opts...)
+	if err == nil || errors.Is(err, validate.ErrNotStruct) {
+		// validation is skipped for non struct types.
+		return nil
+	}
+	return &ConfigError{Err: err}
+}
+
+// Decode decodes the body of r into T and validates it.
+//
+// It returns *[DecodeError] if the body is not a valid JSON for T,
+// or an error joining *validate.ValidationError if the decoded value violates constraints.
+// All violations are collected. opts are passed to [validate.UndValidate] after [validate.CollectAll],
+// so that e.g. [validate.MaxErrors] caps the number of violations.
+//
+// It returns *[ConfigError] if T itself is not valid for validation, e.g. T has a malformed `und` struct tag.
+//
+// Validation is skipped if T is not a struct nor a pointer to a struct.
+func Decode[T any](r *http.Request, opts ...validate.ValidateOption) (T, error) {
+	if err := check[T](opts); err != nil {
+		var v T
+		return v, err
+	}
+	return decode[T](r, opts)
+}
+
+// decode is [Decode] without checking T.
+func decode[T any](r *http.Request, opts []validate.ValidateOption) (T, error) {
+	var v T
+	if r.Body == nil {
+		return v, &DecodeError{Err: errors.New("empty body")}
+	}
+	if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+		return v, &DecodeError{Err: err}
+	}
+	err := validate.UndValidate(v, append([]validate.ValidateOption{validate.CollectAll()}, opts...)...)
+	if errors.Is(err, validate.ErrNotStruct) {
+		return v, nil
+	}
+	return v, err
+}
+
+// FromError converts err returned from [Decode] into Problem.
+//
+//   - *DecodeError results in 400 Bad Request. Its detail does not contain the error returned from encoding/json.
+//   - validation errors result in 422 Unprocessable Entity, with every violation in Errors.
+//   - other errors, including *ConfigError and errors for malformed `und` struct tags ([validate.CodeInvalidTag]),
+//     result in 500 Internal Server Error, without exposing the error.
+func FromError(err error) Problem {
+	var decErr *DecodeError
+	if errors.As(err, &decErr) {
+		return Problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusBadRequest),
+			Status: http.StatusBadRequest,
+			Detail: decodeDetail(decErr),
+		}
+	}
+	internal := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+	}
+	var confErr *ConfigError
+	if errors.As(err, &confErr) {
+		return internal
+	}
+	vErrs := validate.Errors(err)
+	if len(vErrs) == 0 {
+		return internal
+	}
+	for _, vErr := range vErrs {
+		if vErr.Code() == validate.CodeInvalidTag || errors.Is(vErr, validate.ErrNotStruct) {
+			return internal
+		}
+	}
+	p := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusUnprocessableEntity),
+		Status: http.StatusUnprocessableEntity,
+		Detail: fmt.Sprintf("request body has %d invalid value(s)", len(vErrs)),
+		Errors: make([]Error, len(vErrs)),
+	}
+	for i, vErr := range vErrs {
+		p.Errors[i] = Error{
+			Pointer: "#" + vErr.Pointer(),
+			Detail:  vErr.Message(),
+			Code:    vErr.Code(),
+		}
+	}
+	return p
+}
+
+func decodeDetail(err *DecodeError) string {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return "request body is not a valid JSON"
+	case errors.As(err, &typeErr):
+		return "request body has a value of a wrong type"
+	}
+	return "request body could not be decoded"
+}
+
+// Write writes p as an application/problem+json response.
+// If p.Status is zero, 500 is used.
+func Write(w http.ResponseWriter, p Problem) error {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	return json.NewEncoder(w).Encode(p)
+}
+
+// DecodeOrWrite decodes and validates the body of r into T by [Decode].
+// On failure it writes a problem details response to w and returns false.
+func DecodeOrWrite[T any](w http.ResponseWriter, r *http.Request, opts ...validate.ValidateOption) (T, bool) {
+	v, err := Decode[T](r, opts...)
+	return v, writeIfError(w, r, err)
+}
+
+func writeIfError(w http.ResponseWriter, r *http.Request, err error) bool {
+	if err != nil {
+		p := FromError(err)
+		p.Instance = r.URL.Path
+		_ = Write(w, p)
+		return false
+	}
+	return true
+}
+
+// Handler returns a http.Handler which decodes and validates request bodies into T
+// and calls h with the decoded value only if it is valid.
+//
+// T is checked by [validate.UndCheck] only once, when Handler is called.
+// Handler panics if T can not be validated, e.g. T has a malformed `und` struct tag.
+func Handler[T any](h func(w http.ResponseWriter, r *http.Request, v T), opts ...validate.ValidateOption) http.Handler {
+	if err := check[T](opts); err != nil {
+		panic(err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, err := decode[T](r, opts)
+		if !writeIfError(w, r, err) {
+			return
+		}
+		h(w, r, v)
+	})
+}
+
+type ctxKey[T any] struct{}
+
+// Middleware returns a middleware which decodes and validates request bodies into T.
+// The valid value is stored in the request context and can be retrieved by [FromContext].
+//
+// As [Handler], Middleware panics if T can not be validated.
+func Middleware[T any](opts ...validate.ValidateOption) func(next http.Handler) http.Handler {
+	if err := check[T](opts); err != nil {
+		panic(err)
+	}
+	return func(next http.Handler) http.Handler {
+		return Handler(func(w http.ResponseWriter, r *http.Request, v T) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKey[T]{}, v)))
+		}, opts...)
+	}
+}
+
+// FromContext returns the value stored by the middleware returned from [Middleware].
+func FromContext[T any](ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(ctxKey[T]{}).(T)
+	return v, ok
+}
@@ -0,0 +1,167 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ngicks/und"
+	"github.com/ngicks/und/option"
+	"github.com/ngicks/und/validate"
+	"github.com/ngicks/und/validate/problem"
+	"gotest.tools/v3/assert"
+)
+
+type body struct {
+	Name option.Option[string] `json:"name" und:"required"`
+	Age  und.Und[int]          `json:"age" und:"def,und"`
+}
+
+func newServer() http.Handler {
+	return problem.Handler(func(w http.ResponseWriter, r *http.Request, v body) {
+		_, _ = w.Write([]byte(v.Name.Value()))
+	})
+}
+
+func serve(h http.Handler, reqBody string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(reqBody)))
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	rec := serve(newServer(), `{"name":"foo","age":12}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "foo", rec.Body.String())
+
+	rec = serve(newServer(), `{"age":null}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+	var p problem.Problem
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.DeepEqual(
+		t,
+		problem.Problem{
+			Type:     "about:blank",
+			Title:    "Unprocessable Entity",
+			Status:   http.StatusUnprocessableEntity,
+			Detail:   "request body has 2 invalid value(s)",
+			Instance: "/users",
+			Errors: []problem.Error{
+				{Pointer: "#/name", Detail: "input is required", Code: validate.CodeState},
+				{Pointer: "#/age", Detail: "input must be defined or undefined", Code: validate.CodeState},
+			},
+		},
+		p,
+	)
+
+	rec = serve(newServer(), `{"name":`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	p = problem.Problem{}
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Assert(t, len(p.Errors) == 0)
+}
+
+func TestHandler_max_errors(t *testing.T) {
+	h := problem.Handler(func(w http.ResponseWriter, r *http.Request, v body) {}, validate.MaxErrors(1))
+	rec := serve(h, `{"age":null}`)
+	var p problem.Problem
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, 1, len(p.Errors))
+}
+
+func TestMiddleware(t *testing.T) {
+	h := problem.Middleware[body]()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, ok := problem.FromContext[body](r.Context())
+		assert.Assert(t, ok)
+		_, _ = w.Write([]byte(v.Name.Value()))
+	}))
+
+	rec := serve(h, `{"name":"bar"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "bar", rec.Body.String())
+
+	rec = serve(h, `{}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestFromError_internal(t *testing.T) {
+	p := problem.FromError(http.ErrBodyNotAllowed)
+	assert.Equal(t, http.StatusInternalServerError, p.Status)
+	assert.Equal(t, "", p.Detail)
+}
+
+type badTag struct {
+	Name option.Option[string] `json:"name" und:"requried"`
+}
+
+func TestHandler_bad_tag(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"foo"}`))
+	_, ok := problem.DecodeOrWrite[badTag](rec, req)
+	assert.Assert(t, !ok)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	var p problem.Problem
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, "", p.Detail)
+	assert.Equal(t, 0, len(p.Errors))
+
+	_, err := problem.Decode[badTag](httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`)))
+	var confErr *problem.ConfigError
+	assert.Assert(t, errors.As(err, &confErr))
+
+	p = problem.FromError(validate.UndValidate(badTag{}))
+	assert.Equal(t, http.StatusInternalServerError, p.Status)
+	assert.Equal(t, "", p.Detail)
+
+	assert.Assert(t, panics(func() {
+		problem.Handler(func(w http.ResponseWriter, r *http.Request, v badTag) {})
+	}))
+	assert.Assert(t, panics(func() { problem.Middleware[badTag]() }))
+}
+
+func TestHandler_interface(t *testing.T) {
+	v, err := problem.Decode[any](httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"foo"}`)))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[string]any{"name": "foo"}, v)
+
+	v, err = problem.Decode[any](httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`null`)))
+	assert.NilError(t, err)
+	assert.Assert(t, v == nil)
+
+	h := problem.Handler(func(w http.ResponseWriter, r *http.Request, v any) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	rec := serve(h, `[1]`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	assert.Assert(t, !panics(func() { problem.Middleware[any]() }))
+	assert.Assert(t, !panics(func() { problem.Middleware[error]() }))
+}
+
+func panics(fn func()) (panicked bool) {
+	defer func() { panicked = recover() != nil }()
+	fn()
+	return false
+}
+
+func TestFromError_decode_detail(t *testing.T) {
+	for _, tc := range []struct {
+		reqBody string
+		detail  string
+	}{
+		{`{"name":`, "request body is not a valid JSON"},
+		{`{"name":}`, "request body is not a valid JSON"},
+		{`{"name":1}`, "request body has a value of a wrong type"},
+	} {
+		rec := serve(newServer(), tc.reqBody)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		var p problem.Problem
+		assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+		assert.Equal(t, tc.detail, p.Detail)
+	}
+}
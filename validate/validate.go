@@ -1,13 +1,16 @@
 package validate
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
 	"reflect"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/ngicks/und/internal/option"
 	"github.com/ngicks/und/undtag"
 )
 
@@ -26,6 +29,9 @@ type fieldSelector struct {
 type ValidationError struct {
 	fieldChain []fieldSelector
 	err        error
+	code       Code
+	opt        option.Option[undtag.UndOpt]
+	state      string
 }
 
 func ReportState(v any) string {
@@ -69,8 +75,10 @@ func AppendValidationErrorDot(err error, selector string) error {
 	if !ok {
 		return &ValidationError{err: err, fieldChain: []fieldSelector{{fieldSelectorTypeDot, selector}}}
 	}
-	vErr.fieldChain = append(vErr.fieldChain, fieldSelector{fieldSelectorTypeDot, selector})
-	return vErr
+	// copy on append; vErr might be cached and shared.
+	cloned := *vErr
+	cloned.fieldChain = append(slices.Clone(vErr.fieldChain), fieldSelector{fieldSelectorTypeDot, selector})
+	return &cloned
 }
 
 func AppendValidationErrorIndex(err error, selector string) error {
@@ -78,8 +86,10 @@ func AppendValidationErrorIndex(err error, selector string) error {
 	if !ok {
 		return &ValidationError{err: err, fieldChain: []fieldSelector{{fieldSelectorTypeIndex, selector}}}
 	}
-	vErr.fieldChain = append(vErr.fieldChain, fieldSelector{fieldSelectorTypeIndex, selector})
-	return vErr
+	// copy on append; vErr might be cached and shared.
+	cloned := *vErr
+	cloned.fieldChain = append(slices.Clone(vErr.fieldChain), fieldSelector{fieldSelectorTypeIndex, selector})
+	return &cloned
 }
 
 func (e *ValidationError) Unwrap() error {
@@ -120,10 +130,19 @@ func (e *ValidationError) Pointer() string {
 
 var (
 	// ErrNotStruct would be returned by UndValidate and UndCheck
-	// if input is not a struct nor a pointer to a struct.
+	// if input is not a struct nor a pointer to a struct, including nil.
 	ErrNotStruct = errors.New("not struct")
+	// ErrCycle is wrapped in errors reported by UndValidate
+	// if a pointer is visited again while validating values it points to, and [OnCycle] is [CycleError].
+	ErrCycle = errors.New("cycle detected")
+	// ErrMaxDepth is wrapped in errors reported by UndValidate
+	// if the input is nested deeper than the limit set by [MaxDepth].
+	ErrMaxDepth = errors.New("max depth exceeded")
 )
 
+// errNilInput is returned when the input is nil, e.g. a nil interface value.
+var errNilInput = fmt.Errorf("%w: input is nil", ErrNotStruct)
+
 var (
 	// ErrMultipleOption would be returned by UndValidate and UndCheck
 	// if input's `und` struct tags have multiple mutually exclusive options.
@@ -176,40 +195,170 @@ var (
 // Only fields whose struct tag contains `und`, and whose type is implementor of OptionLike, UndLike, ElasticLike,
 // or array, slice, map whose value type are one of implementor,
 // are validated.
-func UndValidate(s any) error {
+// Pointers to implementors are also validated; nil is treated as undefined, or none for option types,
+// unless configured by [NilPointer].
+//
+// Tagged fields of other pointer, slice and map types are treated as option types, where nil is none.
+// len and values options are also applicable to them, strings, and und or option types whose T is a slice or a map,
+// e.g. und.Und[[]T] with `und:"def,len>=1"`.
+//
+// UndValidate recurses into structs in fields, arrays, slices, maps, pointers to them and data container types,
+// reporting indices and map keys in paths.
+//
+// Paths of errors are built from field names in `json` struct tag, falling back to Go field names,
+// so that [ValidationError.Pointer] addresses the value in the encoded JSON document.
+// Fields tagged `json:"-"` are not validated, and fields of embedded structs are treated as fields of the outer struct.
+// Use [FieldNameTag] to read names from another tag key.
+//
+// After checking fields of a struct, UndValidate calls UndValidateStruct method if the struct implements [StructValidator].
+//
+// By default UndValidate returns the first violation it finds.
+// Pass [CollectAll] or [MaxErrors] to collect all violations instead.
+func UndValidate(s any, opts ...ValidateOption) error {
+	if s == nil {
+		return errNilInput
+	}
+	w := newWalker(opts)
 	rv := reflect.ValueOf(s)
-	return cacheValidator(rv.Type()).validate(rv)
+	v := cacheValidator(rv.Type(), w.opts.tagConfig())
+	if v.err != nil {
+		return v.err
+	}
+	return w.result(v.validate(rv, w))
 }
 
 // UndCheck checks whether s is correctly configured with `und` struct tag option without validating it.
-func UndCheck(s any) error {
-	return cacheValidator(reflect.TypeOf(s)).check()
+func UndCheck(s any, opts ...ValidateOption) error {
+	if s == nil {
+		return errNilInput
+	}
+	w := newWalker(opts)
+	return cacheValidator(reflect.TypeOf(s), w.opts.tagConfig()).check()
+}
+
+// UndValidateScope is like [UndValidate] but validates s against options for scope,
+// e.g. "create" or "update", in scoped `und` struct tags like `und:"create:required;update:def,und"`.
+// Fields without segments for scope are validated against unscoped segments.
+//
+// See [undtag.ScopedOption] for the format of scoped tags.
+// Every segment of scoped tags is checked by [undtag.ParseScopes], not only one for scope.
+// Use [AllowedScopes] to reject unknown scope names.
+func UndValidateScope(s any, scope string, opts ...ValidateOption) error {
+	return UndValidate(s, append(opts, withScope(scope))...)
+}
+
+// UndCheckScope is like [UndCheck] but checks options for scope.
+func UndCheckScope(s any, scope string, opts ...ValidateOption) error {
+	return UndCheck(s, append(opts, withScope(scope))...)
 }
 
 var validatorCache sync.Map
 
+type cacheKey struct {
+	rt  reflect.Type
+	cfg tagConfig
+}
+
+// tagConfig decides how struct tags are read. Validators are built and cached per tagConfig.
+type tagConfig struct {
+	nameTag string
+	scope   string
+	// allowedScopes is a sorted, comma separated list of scope names enclosed in commas, e.g. ",create,update,".
+	// It is empty if any scope is allowed.
+	allowedScopes string
+}
+
+// undTag returns options in `und` struct tag of ft for the scope.
+func (c tagConfig) undTag(ft reflect.StructField) string {
+	return undtag.ScopedOption(ft.Tag.Get(undtag.TagName), c.scope)
+}
+
+// parseUndTag parses options in `und` struct tag of ft for the scope.
+// Errors refer to the entire tag, not to options for the scope.
+func (c tagConfig) parseUndTag(ft reflect.StructField) (undtag.UndOpt, error) {
+	return undtag.ParseOptionScope(ft.Tag.Get(undtag.TagName), c.scope)
+}
+
+// checkScopes checks every segment of `und` struct tag of ft, not only one for the scope,
+// and that scopes in the tag are allowed.
+func (c tagConfig) checkScopes(ft reflect.StructField) error {
+	tag := ft.Tag.Get(undtag.TagName)
+	if tag == "" {
+		return nil
+	}
+	scopes, err := undtag.ParseScopes(tag)
+	if err != nil {
+		return err
+	}
+	if c.allowedScopes == "" {
+		return nil
+	}
+	var unknown []string
+	for name := range scopes {
+		if name != "" && !strings.Contains(c.allowedScopes, ","+name+",") {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("%w: %q", undtag.ErrUnknownScope, unknown)
+	}
+	return nil
+}
+
 type cachedValidator struct {
 	rt  reflect.Type
 	err error
 	v   []fieldValidator
+	// groups are struct level validators.
+	groups []func(rv reflect.Value, w *walker) error
+	// hook is true if the type implements StructValidator.
+	hook bool
 }
 
-func (v cachedValidator) validate(rv reflect.Value) error {
+func (v cachedValidator) validate(rv reflect.Value, w *walker) error {
 	if v.err != nil {
-		return v.err
+		return w.report(v.err)
+	}
+	if len(v.v) == 0 && len(v.groups) == 0 && !v.hook {
+		return nil
 	}
 	if rv.Kind() == reflect.Pointer {
 		if rv.IsNil() {
 			// no further stepping
 			return nil
 		}
+		skip, err := w.enter(rv)
+		if skip || err != nil {
+			return err
+		}
+		defer w.leave(rv)
 		rv = rv.Elem()
 	}
+	if w.tooDeep() {
+		return w.report(&ValidationError{err: ErrMaxDepth, code: CodeMaxDepth})
+	}
 	for _, f := range v.v {
-		if err := f.validate(rv.Field(f.i)); err != nil {
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok {
+			// promoted from nil embedded pointer.
+			continue
+		}
+		w.push(fieldSelector{fieldSelectorTypeDot, f.name})
+		err := f.validate(fv, w)
+		w.pop()
+		if err != nil {
 			return err
 		}
 	}
+	for _, g := range v.groups {
+		if err := g(rv, w); err != nil {
+			return err
+		}
+	}
+	if v.hook {
+		return callStructValidator(rv, w)
+	}
 	return nil
 }
 
@@ -221,20 +370,44 @@ func (v cachedValidator) check() error {
 }
 
 type fieldValidator struct {
-	i        int
-	rt       reflect.Type
-	validate func(fv reflect.Value) error
+	// index is the index sequence of the field. See [reflect.Value.FieldByIndex].
+	index    []int
+	name     string
+	validate func(fv reflect.Value, w *walker) error
 }
 
-func cacheValidator(rt reflect.Type) cachedValidator {
-	v, ok := validatorCache.Load(rt)
+func cacheValidator(rt reflect.Type, cfg tagConfig) cachedValidator {
+	key := cacheKey{rt, cfg}
+	v, ok := validatorCache.Load(key)
 	if !ok {
-		v, _ = validatorCache.LoadOrStore(rt, makeValidator(rt, nil))
+		v, _ = validatorCache.LoadOrStore(key, makeValidator(rt, cfg, nil))
 	}
 	return v.(cachedValidator)
 }
 
-func makeValidator(rt reflect.Type, visited map[reflect.Type]*cachedValidator) cachedValidator {
+// mapKey formats k as encoding/json does for map keys.
+func mapKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return ""
+		}
+		if text, err := tm.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10)
+	}
+	return fmt.Sprintf("%v", k.Interface())
+}
+
+func makeValidator(rt reflect.Type, cfg tagConfig, visited map[reflect.Type]*cachedValidator) cachedValidator {
 	if rt.Kind() == reflect.Pointer {
 		rt = rt.Elem()
 	}
@@ -249,12 +422,18 @@ func makeValidator(rt reflect.Type, visited map[reflect.Type]*cachedValidator) c
 	mainValidator := &cachedValidator{}
 	visited[rt] = mainValidator
 
+	fields := visibleFields(rt, cfg.nameTag)
+	groups, err := makeGroupValidators(fields, cfg)
+	if err != nil {
+		return cachedValidator{rt: rt, err: err}
+	}
+
 	var fieldValidators []fieldValidator
-	for i := 0; i < rt.NumField(); i++ {
-		ft := rt.Field(i)
+	for _, f := range fields {
+		ft, name := f.sf, f.name
 
-		if !ft.IsExported() {
-			continue
+		if err := cfg.checkScopes(ft); err != nil {
+			return cachedValidator{rt: rt, err: newTagError(err, name)}
 		}
 
 		isElasticLike := ft.Type.Implements(elasticLike)
@@ -262,74 +441,92 @@ func makeValidator(rt reflect.Type, visited map[reflect.Type]*cachedValidator) c
 		isOptLike := ft.Type.Implements(optionLikeTy)
 		if !isElasticLike && !isUndLike && !isOptLike {
 			ftDeref := ft.Type
-
 			if ftDeref.Kind() == reflect.Pointer {
 				ftDeref = ftDeref.Elem()
-				if ftDeref.Kind() != reflect.Struct {
-					// maybe we'll loosen this restriction
-					// then we could allow *map[K]V or *[]T fields to be validated.
-					continue
-				}
 			}
 
-			subFieldValidator, has := visited[ftDeref]
-			var validateField func(fv reflect.Value) error
-			if !has {
-				switch ftDeref.Kind() {
-				default:
-					continue
-				case reflect.Struct:
-					v := makeValidator(ft.Type, visited)
+			var validators []func(fv reflect.Value, w *walker) error
+
+			plain, err := makePlainValidator(ft, name, cfg)
+			if err != nil {
+				return cachedValidator{rt: rt, err: err}
+			}
+			if plain != nil {
+				validators = append(validators, plain)
+			}
+
+			switch {
+			case ftDeref.Kind() == reflect.Struct:
+				subFieldValidator, has := visited[ftDeref]
+				if !has {
+					v := makeValidator(ft.Type, cfg, visited)
 					if v.err != nil {
-						return cachedValidator{
-							rt: rt,
-							v: []fieldValidator{{
-								i:        i,
-								validate: v.validate,
-							}},
-						}
+						return cachedValidator{rt: rt, err: AppendValidationErrorDot(v.err, name)}
 					}
 					subFieldValidator = &v
-				case reflect.Array, reflect.Slice, reflect.Map:
-					elem := ftDeref.Elem()
-					isElasticLike := elem.Implements(elasticLike)
-					isUndLike := elem.Implements(undLikeTy)
-					isOptLike := elem.Implements(optionLikeTy)
-					hasTag, validator, err := makeFieldValidator(ft, isOptLike, isUndLike, isElasticLike)
-					if !hasTag {
-						continue
-					}
+				}
+				validators = append(validators, func(fv reflect.Value, w *walker) error {
+					return subFieldValidator.validate(fv, w)
+				})
+			case ftDeref.Kind() == reflect.Array, ftDeref.Kind() == reflect.Slice, ftDeref.Kind() == reflect.Map:
+				elem := ftDeref.Elem()
+				var validator func(fv reflect.Value, w *walker) error
+				isElasticLike := elem.Implements(elasticLike)
+				isUndLike := elem.Implements(undLikeTy)
+				isOptLike := elem.Implements(optionLikeTy)
+				if isElasticLike || isUndLike || isOptLike {
+					var (
+						hasTag bool
+						err    error
+					)
+					hasTag, validator, err = makeFieldValidator(name, cfg, ft, elem, isOptLike, isUndLike, isElasticLike)
 					if err != nil {
 						return cachedValidator{rt: rt, err: err}
 					}
-					validateField = func(fv reflect.Value) error {
-						for k, v := range fv.Seq2() {
-							if err := validator(v); err != nil {
-								return AppendValidationErrorIndex(err, fmt.Sprintf("%v", k.Interface()))
-							}
-						}
-						return nil
+					if !hasTag {
+						break
 					}
+				} else if mayContainStruct(elem) {
+					validator = validateValue
+				} else {
+					break
 				}
+				validators = append(validators, func(fv reflect.Value, w *walker) error {
+					if fv.Kind() == reflect.Pointer {
+						if fv.IsNil() {
+							return nil
+						}
+						fv = fv.Elem()
+					}
+					return validateElements(fv, w, validator)
+				})
 			}
 
-			if validateField == nil {
-				validateField = func(fv reflect.Value) error {
-					err := subFieldValidator.validate(fv)
-					if err != nil {
-						return AppendValidationErrorDot(err, ft.Name)
+			var validateField func(fv reflect.Value, w *walker) error
+			switch len(validators) {
+			case 0:
+				continue
+			case 1:
+				validateField = validators[0]
+			default:
+				validateField = func(fv reflect.Value, w *walker) error {
+					for _, v := range validators {
+						if err := v(fv, w); err != nil {
+							return err
+						}
 					}
 					return nil
 				}
 			}
 			fieldValidators = append(fieldValidators, fieldValidator{
-				i:        i,
+				index:    f.index,
+				name:     name,
 				validate: validateField,
 			})
 
 			continue
 		}
-		hasTag, validator, err := makeFieldValidator(ft, isOptLike, isUndLike, isElasticLike)
+		hasTag, validator, err := makeFieldValidator(name, cfg, ft, ft.Type, isOptLike, isUndLike, isElasticLike)
 		if !hasTag {
 			continue
 		}
@@ -339,85 +536,515 @@ func makeValidator(rt reflect.Type, visited map[reflect.Type]*cachedValidator) c
 		fieldValidators = append(
 			fieldValidators,
 			fieldValidator{
-				i:        i,
-				rt:       rt,
+				index:    f.index,
+				name:     name,
 				validate: validator,
 			},
 		)
 	}
 
-	*mainValidator = cachedValidator{rt: rt, v: fieldValidators}
+	*mainValidator = cachedValidator{rt: rt, v: fieldValidators, groups: groups, hook: implementsStructValidator(rt)}
 	return *mainValidator
 }
 
-func makeFieldValidator(ft reflect.StructField, isOptLike, isUndLike, isElasticLike bool) (hasTag bool, validator func(fv reflect.Value) error, err error) {
-	if ft.Type.Kind() == reflect.Pointer {
-		// When field is nil, what should we do? It it considered none or undefined?
-		// I don't have any idea on this. Just return an error.
-		return false, nil, AppendValidationErrorDot(fmt.Errorf("pointer implementor field"), ft.Name)
+// makeFieldValidator makes a validator for values of ty, an implementor type, which is the type of field ft
+// or the element type of ft. name is the name of the field used in errors.
+//
+// ty might be a pointer to an implementor type. nil pointers are handled according to [NilPointer].
+func makeFieldValidator(
+	name string,
+	cfg tagConfig,
+	ft reflect.StructField,
+	ty reflect.Type,
+	isOptLike, isUndLike, isElasticLike bool,
+) (hasTag bool, validator func(fv reflect.Value, w *walker) error, err error) {
+	isPointer := ty.Kind() == reflect.Pointer
+	base := ty
+	if isPointer {
+		base = ty.Elem()
 	}
 
-	tag := ft.Tag.Get(undtag.TagName)
-	if tag == "" {
-		return false, nil, nil
+	if cfg.undTag(ft) == "" {
+		// Without the tag, only values in the container are validated.
+		if !mayContainStruct(base) {
+			return false, nil, nil
+		}
+		validateInner := containerValidator(base)
+		if validateInner == nil {
+			return false, nil, nil
+		}
+		return true, func(fv reflect.Value, w *walker) error {
+			if isPointer {
+				if fv.IsNil() {
+					return nil
+				}
+				fv = fv.Elem()
+			}
+			return validateInner(fv, w)
+		}, nil
 	}
-	opt, err := undtag.ParseOption(tag)
+	opt, err := cfg.parseUndTag(ft)
 	if err != nil {
-		return true, nil, AppendValidationErrorDot(err, ft.Name)
+		return true, nil, newTagError(err, name)
 	}
 
-	if !isElasticLike {
-		if opt.Len().IsSome() {
-			return true, nil, AppendValidationErrorDot(fmt.Errorf("len on non elastic"), ft.Name)
+	// len and values on und or option types are applied to T, e.g. und.Und[[]T].
+	var valueMethod option.Option[reflect.Method]
+	if !isElasticLike && (opt.Len().IsSome() || opt.Values().IsSome()) {
+		m, ok := base.MethodByName("Value")
+		if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 {
+			return true, nil, newTagError(fmt.Errorf("len or values on non elastic"), name)
 		}
-		if opt.Values().IsSome() {
-			return true, nil, AppendValidationErrorDot(fmt.Errorf("values on non elastic"), ft.Name)
+		if err := checkMeasurable(opt, m.Type.Out(0), false); err != nil {
+			return true, nil, newTagError(fmt.Errorf("non elastic: %w", err), name)
+		}
+		valueMethod = option.Some(m)
+	}
+	if values, ok := opt.Values().Get(); ok && isElasticLike {
+		if m, ok := base.MethodByName("Pointers"); ok && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 && m.Type.Out(0).Kind() == reflect.Slice {
+			if err := values.Check(m.Type.Out(0).Elem()); err != nil {
+				return true, nil, newTagError(err, name)
+			}
 		}
 	}
 
-	var validateOpt func(fv reflect.Value) error
+	var validateOpt func(v any) bool
 	switch {
 	case isElasticLike:
-		validateOpt = func(fv reflect.Value) error {
-			if !opt.ValidElastic(fv.Interface().(ElasticLike)) {
-				return AppendValidationErrorDot(fmt.Errorf("input %s", opt.Describe()), ft.Name)
-			}
-			return nil
+		validateOpt = func(v any) bool {
+			return opt.ValidElastic(v.(ElasticLike))
 		}
 	case isUndLike:
-		validateOpt = func(fv reflect.Value) error {
-			if !opt.ValidUnd(fv.Interface().(UndLike)) {
-				return AppendValidationErrorDot(fmt.Errorf("input %s", opt.Describe()), ft.Name)
-			}
-			return nil
+		validateOpt = func(v any) bool {
+			// states might be omitted if len or values is specified.
+			return opt.States().IsNone() || opt.ValidUnd(v.(UndLike))
 		}
 	case isOptLike:
-		validateOpt = func(fv reflect.Value) error {
-			if !opt.ValidOpt(fv.Interface().(OptionLike)) {
-				return AppendValidationErrorDot(fmt.Errorf("input %s", opt.Describe()), ft.Name)
-			}
-			return nil
+		validateOpt = func(v any) bool {
+			return opt.States().IsNone() || opt.ValidOpt(v.(OptionLike))
 		}
 	}
 
-	validate := validateOpt
-	if ft.Type.Implements(validatorUndTy) {
-		validate = func(fv reflect.Value) error {
-			err := validateOpt(fv)
-			if err != nil {
+	report := func(v any, w *walker) error {
+		return reportViolation(w, opt, violatedCode(opt, v), reportState(v))
+	}
+
+	validateInner := containerValidator(base)
+	validate := func(fv reflect.Value, w *walker) error {
+		if isPointer {
+			if fv.IsNil() {
+				if w.opts.nilPolicy == NilInvalid {
+					return w.report(&ValidationError{
+						err:   fmt.Errorf("input must not be nil"),
+						code:  CodeState,
+						opt:   option.Some(opt),
+						state: "nil",
+					})
+				}
+				v := nilValue{null: w.opts.nilPolicy == NilAsNull, option: isOptLike && !isUndLike}
+				if !validateOpt(v) {
+					return report(v, w)
+				}
+				return validateCustom(opt, v, fv, w)
+			}
+			fv = fv.Elem()
+		}
+		v := fv.Interface()
+		if !validateOpt(v) {
+			return report(v, w)
+		}
+		if m, ok := valueMethod.Get(); ok && isFilled(v) {
+			valid, err := validateLenValues(opt, fv.Method(m.Index).Call(nil)[0], w)
+			if !valid || err != nil {
 				return err
 			}
-			return fv.Interface().(UndValidator).UndValidate()
 		}
+		if err := validateCustom(opt, v, fv, w); err != nil {
+			return err
+		}
+		if validateInner != nil {
+			return validateInner(fv, w)
+		}
+		return nil
 	}
 
-	if ft.Type.Implements(checkerUndTy) {
+	if base.Implements(checkerUndTy) {
 		// keep it addressable. The type might implement it on pointer type.
-		fv := reflect.New(ft.Type).Elem()
+		fv := reflect.New(base).Elem()
 		err := fv.Interface().(UndChecker).UndCheck()
 		if err != nil {
-			return true, nil, AppendValidationErrorDot(err, ft.Name)
+			return true, nil, AppendValidationErrorDot(err, name)
 		}
 	}
 	return true, validate, nil
 }
+
+// makePlainValidator makes a validator for the field ft whose type is not an implementor type
+// but is tagged with `und` struct tag.
+//
+// Pointers, slices, maps and interfaces are treated as option types, where nil is none and non-nil is some.
+// Other types, e.g. strings, arrays, numbers and bools, are always some.
+// len and values are applied to pointed values, slices, maps, strings and arrays.
+//
+// It returns nil if the field does not have the tag.
+// Slices, arrays and maps of implementor types are also excluded; the tag is applied to their elements.
+// It returns an error for channels, functions and unsafe pointers.
+func makePlainValidator(ft reflect.StructField, name string, cfg tagConfig) (func(fv reflect.Value, w *walker) error, error) {
+	if cfg.undTag(ft) == "" {
+		return nil, nil
+	}
+	ty := ft.Type
+	measured := ty
+	switch ty.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return nil, newTagError(fmt.Errorf("und tag on %s", ty), name)
+	case reflect.Pointer:
+		measured = ty.Elem()
+	case reflect.Array, reflect.Slice, reflect.Map:
+		elem := ty.Elem()
+		if elem.Implements(elasticLike) || elem.Implements(undLikeTy) || elem.Implements(optionLikeTy) {
+			return nil, nil
+		}
+	}
+
+	opt, err := cfg.parseUndTag(ft)
+	if err != nil {
+		return nil, newTagError(err, name)
+	}
+	if opt.Len().IsSome() || opt.Values().IsSome() {
+		if err := checkMeasurable(opt, measured, true); err != nil {
+			return nil, newTagError(err, name)
+		}
+	}
+
+	return func(fv reflect.Value, w *walker) error {
+		v := plainValue{some: true}
+		switch fv.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			v.some = !fv.IsNil()
+		}
+		if opt.States().IsSome() && !opt.ValidOpt(v) {
+			return reportViolation(w, opt, CodeState, ReportState(v))
+		}
+		if !v.some {
+			return validatePlainCustom(opt, nil, undtag.StateUndefined, w)
+		}
+		if fv.Kind() == reflect.Pointer {
+			fv = fv.Elem()
+		}
+		valid, err := validateLenValues(opt, fv, w)
+		if !valid || err != nil {
+			return err
+		}
+		return validatePlainCustom(opt, fv.Interface(), undtag.StateDefined, w)
+	}, nil
+}
+
+// checkMeasurable checks len and values options in opt are applicable to rt.
+// len is applicable to strings only if allowString is true.
+func checkMeasurable(opt undtag.UndOpt, rt reflect.Type, allowString bool) error {
+	if opt.Len().IsSome() {
+		switch rt.Kind() {
+		case reflect.Array, reflect.Slice, reflect.Map:
+		case reflect.String:
+			if !allowString {
+				return fmt.Errorf("len on %s", rt)
+			}
+		default:
+			return fmt.Errorf("len on %s", rt)
+		}
+	}
+	if values, ok := opt.Values().Get(); ok {
+		switch rt.Kind() {
+		case reflect.Array, reflect.Slice, reflect.Map:
+		default:
+			return fmt.Errorf("values on %s", rt)
+		}
+		if values.Sorted && rt.Kind() == reflect.Map {
+			return fmt.Errorf("%w: values:sorted on %s", ErrMalformedValues, rt)
+		}
+		if err := values.Check(rt.Elem()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateLenValues validates rv, a slice, array, map or string, against len and values options in opt.
+// valid is false if a violation is reported.
+func validateLenValues(opt undtag.UndOpt, rv reflect.Value, w *walker) (valid bool, err error) {
+	m := measuredValue{rv}
+	if l, ok := opt.Len().Get(); ok && !l.Valid(m) {
+		return false, reportViolation(w, opt, CodeLen, ReportState(m))
+	}
+	if values, ok := opt.Values().Get(); ok && !values.Valid(m) {
+		return false, reportViolation(w, opt, CodeValues, ReportState(m))
+	}
+	return true, nil
+}
+
+func reportViolation(w *walker, opt undtag.UndOpt, code Code, state string) error {
+	vErr := &ValidationError{
+		err:   fmt.Errorf("input %s", opt.Describe()),
+		code:  code,
+		opt:   option.Some(opt),
+		state: state,
+	}
+	if err := w.report(vErr); err != nil {
+		return err
+	}
+	// No point to further inspect invalid value.
+	return nil
+}
+
+// isFilled reports whether v, an und or option type, is defined or some.
+func isFilled(v any) bool {
+	switch x := v.(type) {
+	case UndLike:
+		return x.IsDefined()
+	case OptionLike:
+		return x.IsSome()
+	}
+	return false
+}
+
+// plainValue is state of a non implementor value, e.g. *T or []T.
+type plainValue struct {
+	some bool
+}
+
+var _ OptionLike = plainValue{}
+
+func (v plainValue) IsNone() bool { return !v.some }
+func (v plainValue) IsSome() bool { return v.some }
+
+// measuredValue wraps a defined slice, array, map or string so that it can be validated as an elastic value.
+type measuredValue struct {
+	rv reflect.Value
+}
+
+var _ ElasticLike = measuredValue{}
+
+func (v measuredValue) IsDefined() bool   { return true }
+func (v measuredValue) IsNull() bool      { return false }
+func (v measuredValue) IsUndefined() bool { return false }
+func (v measuredValue) Len() int          { return v.rv.Len() }
+
+// Elements returns v itself so that undtag.ValuesValidator can inspect elements.
+func (v measuredValue) Elements() reflect.Value { return v.rv }
+func (v measuredValue) HasNull() bool {
+	switch v.rv.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+	default:
+		return false
+	}
+	for _, e := range v.rv.Seq2() {
+		switch e.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func:
+			if e.IsNil() {
+				return true
+			}
+		}
+		if !e.CanInterface() {
+			continue
+		}
+		switch x := e.Interface().(type) {
+		case UndLike:
+			if x.IsNull() {
+				return true
+			}
+		case OptionLike:
+			if x.IsNone() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nilValue stands for a nil pointer to an implementor type.
+// It is undefined, or null if null is true. For option types, it is none.
+type nilValue struct {
+	null   bool
+	option bool
+}
+
+var _ ElasticLike = nilValue{}
+
+// reportState is same as ReportState but reports nilValue for option types as none.
+func reportState(v any) string {
+	if n, ok := v.(nilValue); ok && n.option {
+		return "none"
+	}
+	return ReportState(v)
+}
+
+func (v nilValue) IsDefined() bool   { return false }
+func (v nilValue) IsNull() bool      { return v.null }
+func (v nilValue) IsUndefined() bool { return !v.null }
+func (v nilValue) Len() int          { return 0 }
+func (v nilValue) HasNull() bool     { return false }
+func (v nilValue) IsNone() bool      { return true }
+func (v nilValue) IsSome() bool      { return false }
+
+// makeContainerValidator makes a validator for values stored in a container type ty.
+// It returns nil if ty does not implement UndValidator.
+//
+// Rather than calling UndValidate method, the validator unwraps values
+// so that it can report all violations with their paths.
+// If ty is not one of known shapes, it falls back to calling UndValidate method.
+func makeContainerValidator(ty reflect.Type) func(fv reflect.Value, w *walker) error {
+	if !ty.Implements(validatorUndTy) {
+		return nil
+	}
+
+	fallback := func(fv reflect.Value, w *walker) error {
+		if err := fv.Interface().(UndValidator).UndValidate(); err != nil {
+			return w.report(err)
+		}
+		return nil
+	}
+
+	if ty.Implements(elasticLike) {
+		m, ok := ty.MethodByName("Pointers")
+		if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 || m.Type.Out(0).Kind() != reflect.Slice {
+			return fallback
+		}
+		return func(fv reflect.Value, w *walker) error {
+			ptrs := fv.Method(m.Index).Call(nil)[0]
+			for i := range ptrs.Len() {
+				p := ptrs.Index(i)
+				if p.IsNil() {
+					continue
+				}
+				w.push(fieldSelector{fieldSelectorTypeIndex, strconv.FormatInt(int64(i), 10)})
+				err := validateValue(p.Elem(), w)
+				w.pop()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	m, ok := ty.MethodByName("Value")
+	if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 {
+		return fallback
+	}
+	return func(fv reflect.Value, w *walker) error {
+		switch x := fv.Interface().(type) {
+		case UndLike:
+			if !x.IsDefined() {
+				return nil
+			}
+		case OptionLike:
+			if !x.IsSome() {
+				return nil
+			}
+		}
+		return validateValue(fv.Method(m.Index).Call(nil)[0], w)
+	}
+}
+
+var containerValidatorCache sync.Map
+
+// containerValidator is cached version of makeContainerValidator.
+func containerValidator(ty reflect.Type) func(fv reflect.Value, w *walker) error {
+	v, ok := containerValidatorCache.Load(ty)
+	if !ok {
+		v, _ = containerValidatorCache.LoadOrStore(ty, makeContainerValidator(ty))
+	}
+	return v.(func(fv reflect.Value, w *walker) error)
+}
+
+func isContainer(rt reflect.Type) bool {
+	return rt.Implements(elasticLike) || rt.Implements(undLikeTy) || rt.Implements(optionLikeTy)
+}
+
+// mayContainStruct reports whether values of rt might have structs in it,
+// directly or through pointers, arrays, slices, maps or container types.
+func mayContainStruct(rt reflect.Type) bool {
+	return mayContainStructVisited(rt, map[reflect.Type]bool{})
+}
+
+func mayContainStructVisited(rt reflect.Type, visited map[reflect.Type]bool) bool {
+	for rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if visited[rt] {
+		return false
+	}
+	visited[rt] = true
+	if isContainer(rt) {
+		m, ok := rt.MethodByName("Value")
+		if !ok || m.Type.NumIn() != 1 || m.Type.NumOut() != 1 {
+			// unknown container; its UndValidate method might validate something.
+			return rt.Implements(validatorUndTy)
+		}
+		return mayContainStructVisited(m.Type.Out(0), visited)
+	}
+	switch rt.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return mayContainStructVisited(rt.Elem(), visited)
+	}
+	return false
+}
+
+// validateElements calls fn for each element of rv, an array, a slice or a map,
+// pushing its index or key to the path.
+func validateElements(rv reflect.Value, w *walker, fn func(rv reflect.Value, w *walker) error) error {
+	if w.tooDeep() {
+		return w.report(&ValidationError{err: ErrMaxDepth, code: CodeMaxDepth})
+	}
+	isMap := rv.Kind() == reflect.Map
+	for k, v := range rv.Seq2() {
+		var sel string
+		if isMap {
+			sel = mapKey(k)
+		} else {
+			sel = strconv.FormatInt(k.Int(), 10)
+		}
+		w.push(fieldSelector{fieldSelectorTypeIndex, sel})
+		err := fn(v, w)
+		w.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateValue validates rv, a value stored in a container or a collection.
+// It recurses into structs, and elements of collections and container types.
+func validateValue(rv reflect.Value, w *walker) error {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		if elem := rv.Type().Elem(); elem.Kind() == reflect.Struct && !isContainer(elem) {
+			// pass it as a pointer so that cycles can be detected.
+			return cacheValidator(elem, w.opts.tagConfig()).validate(rv, w)
+		}
+		rv = rv.Elem()
+	}
+	rt := rv.Type()
+	if isContainer(rt) {
+		if v := containerValidator(rt); v != nil {
+			return v(rv, w)
+		}
+		return nil
+	}
+	switch rt.Kind() {
+	case reflect.Struct:
+		return cacheValidator(rt, w.opts.tagConfig()).validate(rv, w)
+	case reflect.Array, reflect.Slice, reflect.Map:
+		if !mayContainStruct(rt.Elem()) {
+			return nil
+		}
+		return validateElements(rv, w, validateValue)
+	}
+	return nil
+}
@@ -1,16 +1,38 @@
 package validate_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/ngicks/und"
 	"github.com/ngicks/und/option"
 	"github.com/ngicks/und/sliceund/elastic"
+	"github.com/ngicks/und/undtag"
 	"github.com/ngicks/und/validate"
 	"gotest.tools/v3/assert"
 )
 
+// pointers returns JSON pointers of validation errors in err.
+func pointers(err error) []string {
+	var out []string
+	for _, vErr := range validate.Errors(err) {
+		out = append(out, vErr.Pointer())
+	}
+	return out
+}
+
+// pointersAndCodes is like pointers but each pointer is followed by ":" and the code of the error.
+func pointersAndCodes(err error) []string {
+	var out []string
+	for _, vErr := range validate.Errors(err) {
+		out = append(out, vErr.Pointer()+":"+string(vErr.Code()))
+	}
+	return out
+}
+
 type All struct {
 	OptRequired       option.Option[string] `und:"required"`
 	OptNullish        option.Option[string] `und:"nullish"`
@@ -541,6 +563,14 @@ func TestValidate_elasticTag(t *testing.T) {
 	assert.NilError(t, validate.UndValidate(e))
 }
 
+func TestValidate_not_struct(t *testing.T) {
+	var e error
+	for _, v := range []any{nil, e, 1, []All{{}}} {
+		assert.ErrorIs(t, validate.UndValidate(v), validate.ErrNotStruct)
+		assert.ErrorIs(t, validate.UndCheck(v), validate.ErrNotStruct)
+	}
+}
+
 func TestReportState(t *testing.T) {
 	assert.Equal(t, "", validate.ReportState(""))
 	assert.Equal(t, "some", validate.ReportState(option.Some(10)))
@@ -564,3 +594,795 @@ func TestValidationError(t *testing.T) {
 	assert.Equal(t, "validation failed at .A[5].N/N.~.B.C: foo", err.Error())
 	assert.Equal(t, "/A/5/N~1N/~0/B/C", err.(*validate.ValidationError).Pointer())
 }
+
+type collectAll struct {
+	A option.Option[string]            `und:"required"`
+	B und.Und[string]                  `und:"def"`
+	S []und.Und[string]                `und:"def"`
+	E elastic.Elastic[ChildB]          `und:"required"`
+	N option.Option[ChildA]            `und:"required"`
+	M map[string]option.Option[string] `und:"required"`
+}
+
+func TestValidate_collect_all(t *testing.T) {
+	v := collectAll{
+		S: []und.Und[string]{und.Defined("foo"), und.Null[string](), und.Undefined[string]()},
+		E: elastic.FromValues(ChildB{C: option.Some("foo")}, ChildB{}),
+		N: option.Some(ChildA{}),
+	}
+
+	err := validate.UndValidate(v)
+	assert.ErrorContains(t, err, "validation failed at .A:")
+	assert.Equal(t, 1, len(validate.Errors(err)))
+
+	err = validate.UndValidate(v, validate.CollectAll())
+	t.Logf("err = %v", err)
+	assert.DeepEqual(
+		t,
+		[]string{"/A", "/B", "/S/1", "/S/2", "/E/1/C", "/N/B"},
+		pointers(err),
+	)
+
+	err = validate.UndValidate(v, validate.MaxErrors(3))
+	assert.Equal(t, 3, len(validate.Errors(err)))
+
+	assert.NilError(t, validate.UndValidate(valid, validate.CollectAll()))
+}
+
+type (
+	jsonNamed struct {
+		A       option.Option[string]         `json:"a" und:"required"`
+		B       option.Option[string]         `json:"-" und:"required"`
+		C       option.Option[string]         `json:",omitempty" und:"required"`
+		M       map[int]option.Option[string] `json:"m" yaml:"map" und:"required"`
+		Nested  option.Option[jsonNamedChild] `json:"nested" yaml:"n" und:"required"`
+		Ignored option.Option[string]         `yaml:"-" und:"required"`
+		jsonNamedEmbedded
+		Sub jsonNamedChild `json:"sub" yaml:",inline"`
+	}
+	jsonNamedChild struct {
+		D option.Option[string] `json:"d/~" yaml:"d" und:"required"`
+	}
+	jsonNamedEmbedded struct {
+		E und.Und[string] `json:"e" und:"def"`
+	}
+)
+
+func TestValidate_field_names(t *testing.T) {
+	v := jsonNamed{
+		M:      map[int]option.Option[string]{5: option.None[string]()},
+		Nested: option.Some(jsonNamedChild{}),
+	}
+	err := validate.UndValidate(v, validate.CollectAll())
+	t.Logf("err = %v", err)
+	assert.DeepEqual(
+		t,
+		[]string{"/a", "/C", "/m/5", "/nested/d~1~0", "/Ignored", "/e", "/sub/d~1~0"},
+		pointers(err),
+	)
+
+	err = validate.UndValidate(v, validate.CollectAll(), validate.FieldNameTag("yaml"))
+	t.Logf("err = %v", err)
+	assert.DeepEqual(
+		t,
+		[]string{"/A", "/B", "/C", "/map/5", "/n/d", "/E", "/d"},
+		pointers(err),
+	)
+}
+
+type structured struct {
+	A und.Und[string]         `json:"a" und:"def"`
+	E elastic.Elastic[string] `json:"e" und:"required,len>=2"`
+	V elastic.Elastic[string] `json:"v" und:"values:nonnull"`
+}
+
+func TestValidationError_structured(t *testing.T) {
+	err := validate.UndValidate(
+		structured{
+			A: und.Null[string](),
+			E: elastic.FromValue("foo"),
+			V: elastic.FromOptions(option.None[string]()),
+		},
+		validate.CollectAll(),
+	)
+	errs := validate.Errors(err)
+	assert.Equal(t, 3, len(errs))
+
+	assert.DeepEqual(t, []string{"a"}, errs[0].Path())
+	assert.Equal(t, validate.CodeState, errs[0].Code())
+	assert.Equal(t, "null", errs[0].State())
+	opt, ok := errs[0].Constraint()
+	assert.Assert(t, ok)
+	assert.Assert(t, opt.States().Value().Def)
+	assert.Equal(t, validate.CodeLen, errs[1].Code())
+	assert.Equal(t, validate.CodeValues, errs[2].Code())
+
+	bin, jsonErr := json.Marshal(errs)
+	assert.NilError(t, jsonErr)
+	assert.Equal(
+		t,
+		`[`+
+			`{"path":["a"],"pointer":"/a","code":"state","constraint":{"states":["defined"]},"state":"null","message":"input must be defined"},`+
+			`{"path":["e"],"pointer":"/e","code":"len","constraint":{"states":["defined"],"len":{"op":"\u003e=","len":2}},"state":"defined, len=1, has null=false","message":"input is required, and defined or must have length of \u003e= 2"},`+
+			`{"path":["v"],"pointer":"/v","code":"values","constraint":{"values":{"nonnull":true}},"state":"defined, len=1, has null=true","message":"input must not contain null"}`+
+			`]`,
+		string(bin),
+	)
+
+	err = validate.UndCheck(invalidMalformedLen1{})
+	assert.Equal(t, validate.CodeInvalidTag, validate.Errors(err)[0].Code())
+	_, ok = validate.Errors(err)[0].Constraint()
+	assert.Assert(t, !ok)
+
+	assert.Equal(t, validate.CodeUnknown, validate.NewValidationError(fmt.Errorf("foo")).Code())
+}
+
+type pointerImplementor struct {
+	O *option.Option[string]              `json:"o" und:"null"`
+	U *und.Und[string]                    `json:"u" und:"und"`
+	E *elastic.Elastic[string]            `json:"e" und:"und,len==1"`
+	N *option.Option[ChildB]              `json:"n" und:"def,null"`
+	S []*und.Und[string]                  `json:"s" und:"def,und"`
+	M map[string]*elastic.Elastic[string] `json:"m" und:"und"`
+}
+
+func TestValidate_pointer_implementor(t *testing.T) {
+	assert.NilError(t, validate.UndCheck(pointerImplementor{}))
+	assert.NilError(t, validate.UndValidate(pointerImplementor{}))
+	fu := und.Defined("foo")
+	nu := und.Null[string]()
+	assert.NilError(t, validate.UndValidate(pointerImplementor{
+		S: []*und.Und[string]{nil, &fu},
+		M: map[string]*elastic.Elastic[string]{"foo": nil},
+	}))
+
+	fe := elastic.FromValues("foo", "bar")
+	fn := option.Some(ChildB{})
+	fo := option.Some("foo")
+	err := validate.UndValidate(
+		pointerImplementor{
+			O: &fo,
+			U: &fu,
+			E: &fe,
+			N: &fn,
+			S: []*und.Und[string]{&nu},
+		},
+		validate.CollectAll(),
+	)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/o", "/u", "/e", "/n/C", "/s/0"}, pointers(err))
+
+	err = validate.UndValidate(pointerImplementor{}, validate.CollectAll(), validate.NilPointer(validate.NilAsNull))
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/u", "/e"}, pointers(err))
+	assert.Equal(t, "null", validate.Errors(err)[0].State())
+
+	err = validate.UndValidate(
+		pointerImplementor{
+			S: []*und.Und[string]{nil},
+		},
+		validate.CollectAll(),
+		validate.NilPointer(validate.NilInvalid),
+	)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/o", "/u", "/e", "/n", "/s/0"}, pointers(err))
+}
+
+type plainTagged struct {
+	P  *string               `json:"p" und:"required"`
+	PS *[]string             `json:"ps" und:"und,len>=1"`
+	S  []string              `json:"s" und:"def,len<=2"`
+	SP []*string             `json:"sp" und:"values:nonnull"`
+	M  map[string]int        `json:"m" und:"null,und"`
+	St string                `json:"st" und:"len<=3"`
+	C  *ChildB               `json:"c" und:"def"`
+	U  und.Und[[]string]     `json:"u" und:"def,len>=1"`
+	O  option.Option[[]*int] `json:"o" und:"def,und,values:nonnull"`
+}
+
+func TestValidate_plain(t *testing.T) {
+	foo := "foo"
+	valid := plainTagged{
+		P:  &foo,
+		S:  []string{"foo"},
+		SP: []*string{&foo},
+		St: "foo",
+		C:  &ChildB{C: option.Some("bar")},
+		U:  und.Defined([]string{"foo"}),
+	}
+	assert.NilError(t, validate.UndCheck(valid))
+	assert.NilError(t, validate.UndValidate(valid))
+
+	err := validate.UndValidate(
+		plainTagged{
+			PS: &[]string{},
+			S:  []string{"foo", "bar", "baz"},
+			SP: []*string{nil},
+			M:  map[string]int{},
+			St: "fooo",
+			C:  &ChildB{},
+			U:  und.Defined([]string{}),
+			O:  option.Some([]*int{nil}),
+		},
+		validate.CollectAll(),
+	)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(
+		t,
+		[]string{
+			"/p:state",
+			"/ps:len",
+			"/s:len",
+			"/sp:values",
+			"/m:state",
+			"/st:len",
+			"/c/C:state",
+			"/u:len",
+			"/o:values",
+		},
+		pointersAndCodes(err),
+	)
+
+	err = validate.UndValidate(plainTagged{P: &foo}, validate.CollectAll())
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/s:state", "/c:state", "/u:state"}, pointersAndCodes(err))
+}
+
+type (
+	invalidLenOnPlain struct {
+		A *int `und:"len==1"`
+	}
+	invalidValuesOnString struct {
+		A string `und:"values:nonnull"`
+	}
+	invalidLenOnUndString struct {
+		A und.Und[string] `und:"len==1"`
+	}
+)
+
+func TestValidate_plain_invalid_options(t *testing.T) {
+	for _, tt := range []any{
+		invalidLenOnPlain{},
+		invalidValuesOnString{},
+		invalidLenOnUndString{},
+	} {
+		err := validate.UndCheck(tt)
+		t.Logf("err = %v", err)
+		assert.Assert(t, err != nil)
+		assert.Equal(t, validate.CodeInvalidTag, validate.Errors(err)[0].Code())
+	}
+}
+
+type (
+	collections struct {
+		S  []ChildB                                     `json:"s"`
+		SP []*ChildB                                    `json:"sp"`
+		A  [2]ChildB                                    `json:"a"`
+		M  map[string]ChildB                            `json:"m"`
+		PS *[]ChildB                                    `json:"ps"`
+		SS [][]ChildB                                   `json:"ss"`
+		O  option.Option[[]ChildB]                      `json:"o"`
+		U  und.Und[map[string]ChildB]                   `json:"u" und:"def,und"`
+		E  elastic.Elastic[[]ChildB]                    `json:"e"`
+		OU option.Option[[]und.Und[ChildB]]             `json:"ou"`
+		MO map[string]option.Option[[]ChildB]           `json:"mo"`
+		I  []int                                        `json:"i"`
+		X  option.Option[map[string]option.Option[int]] `json:"x"`
+	}
+)
+
+func TestValidate_collections(t *testing.T) {
+	ok := ChildB{C: option.Some("foo")}
+	ng := ChildB{}
+	v := collections{
+		S:  []ChildB{ok, ng},
+		SP: []*ChildB{nil, &ng},
+		A:  [2]ChildB{ok, ok},
+		M:  map[string]ChildB{"foo": ng},
+		PS: &[]ChildB{ng},
+		SS: [][]ChildB{{ok}, {ok, ng}},
+		O:  option.Some([]ChildB{ng}),
+		U:  und.Defined(map[string]ChildB{"bar": ng}),
+		E:  elastic.FromValues([]ChildB{ok}, []ChildB{ng}),
+		OU: option.Some([]und.Und[ChildB]{und.Null[ChildB](), und.Defined(ng)}),
+		MO: map[string]option.Option[[]ChildB]{"baz": option.Some([]ChildB{ng})},
+		I:  []int{1, 2, 3},
+	}
+	assert.NilError(t, validate.UndCheck(v))
+	err := validate.UndValidate(v, validate.CollectAll())
+	t.Logf("err = %v", err)
+	assert.DeepEqual(
+		t,
+		[]string{
+			"/s/1/C",
+			"/sp/1/C",
+			"/m/foo/C",
+			"/ps/0/C",
+			"/ss/1/1/C",
+			"/o/0/C",
+			"/u/bar/C",
+			"/e/1/0/C",
+			"/ou/1/C",
+			"/mo/baz/0/C",
+		},
+		pointers(err),
+	)
+
+	v = collections{
+		S: []ChildB{ok},
+		A: [2]ChildB{ok, ok},
+		O: option.Some([]ChildB{ok}),
+	}
+	assert.NilError(t, validate.UndValidate(v))
+}
+
+type graphNode struct {
+	V        option.Option[string] `json:"v" und:"required"`
+	Parent   *graphNode            `json:"parent"`
+	Children []*graphNode          `json:"children"`
+}
+
+func TestValidate_cycle(t *testing.T) {
+	root := &graphNode{V: option.Some("root")}
+	shared := &graphNode{V: option.None[string](), Parent: root}
+	root.Children = []*graphNode{
+		{V: option.Some("a"), Parent: root, Children: []*graphNode{shared}},
+		{V: option.Some("b"), Parent: root, Children: []*graphNode{shared}},
+	}
+
+	err := validate.UndValidate(root, validate.CollectAll())
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/children/0/children/0/v:state"}, pointersAndCodes(err))
+
+	err = validate.UndValidate(root, validate.CollectAll(), validate.OnCycle(validate.CycleError))
+	t.Logf("err = %v", err)
+	assert.ErrorIs(t, err, validate.ErrCycle)
+	assert.DeepEqual(
+		t,
+		[]string{
+			"/children/0/parent:cycle",
+			"/children/0/children/0/v:state",
+			"/children/0/children/0/parent:cycle",
+			"/children/1/parent:cycle",
+		},
+		pointersAndCodes(err),
+	)
+
+	// a value, not a pointer, can not be a part of a cycle by itself.
+	shared.V = option.Some("shared")
+	assert.NilError(t, validate.UndValidate(*root))
+}
+
+func TestValidate_max_depth(t *testing.T) {
+	root := &graphNode{V: option.Some("root")}
+	cur := root
+	for range 10 {
+		next := &graphNode{V: option.Some("child")}
+		cur.Children = []*graphNode{next}
+		cur = next
+	}
+	cur.V = option.None[string]()
+
+	assert.ErrorContains(t, validate.UndValidate(root), "input is required")
+
+	err := validate.UndValidate(root, validate.MaxDepth(5))
+	t.Logf("err = %v", err)
+	assert.ErrorIs(t, err, validate.ErrMaxDepth)
+	assert.Equal(t, validate.CodeMaxDepth, validate.Errors(err)[0].Code())
+}
+
+type (
+	embeddedOuter struct {
+		Outer option.Option[string] `json:"outer" und:"required"`
+		*EmbeddedPtr
+		embeddedUnexported
+		EmbeddedA
+		EmbeddedB
+		Named EmbeddedPtr `json:"named"`
+	}
+	EmbeddedPtr struct {
+		P option.Option[string] `json:"p" und:"required"`
+	}
+	embeddedUnexported struct {
+		U option.Option[string] `json:"u" und:"required"`
+		// shadowed by embeddedOuter.Outer
+		Outer option.Option[string] `json:"outer" und:"null"`
+	}
+	EmbeddedA struct {
+		// conflicts with EmbeddedB.Dup at the same depth; both are dropped.
+		Dup option.Option[string] `und:"required"`
+		// tagged one wins.
+		T option.Option[string] `json:"T" und:"required"`
+	}
+	EmbeddedB struct {
+		Dup option.Option[string] `und:"required"`
+		T   option.Option[string] `und:"null"`
+	}
+)
+
+func TestValidate_embedded_promotion(t *testing.T) {
+	assert.NilError(t, validate.UndCheck(embeddedOuter{}))
+
+	// nil embedded pointer: its fields are not validated.
+	err := validate.UndValidate(embeddedOuter{}, validate.CollectAll())
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/outer", "/u", "/T", "/named/p"}, pointers(err))
+
+	err = validate.UndValidate(embeddedOuter{EmbeddedPtr: &EmbeddedPtr{}}, validate.CollectAll())
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/outer", "/p", "/u", "/T", "/named/p"}, pointers(err))
+
+	assert.NilError(t, validate.UndValidate(embeddedOuter{
+		Outer:              option.Some("foo"),
+		EmbeddedPtr:        &EmbeddedPtr{P: option.Some("foo")},
+		embeddedUnexported: embeddedUnexported{U: option.Some("foo"), Outer: option.Some("shadowed")},
+		EmbeddedA:          EmbeddedA{T: option.Some("foo")},
+		EmbeddedB:          EmbeddedB{T: option.Some("not validated")},
+		Named:              EmbeddedPtr{P: option.Some("foo")},
+	}))
+}
+
+type (
+	payment struct {
+		Card   option.Option[string] `json:"card" und:"def,und,group=payment:exactly-one"`
+		Bank   und.Und[string]       `json:"bank" und:"def,null,und,group=payment:exactly-one"`
+		Wallet *string               `json:"wallet" und:"group=payment:exactly-one,group=contact:at-least-one"`
+		Email  option.Option[string] `json:"email" und:"group=contact:at-least-one"`
+		Street option.Option[string] `json:"street" und:"requires=City,group=address:all-or-none"`
+		City   option.Option[string] `json:"city" und:"group=address:all-or-none"`
+		Zip    option.Option[string] `json:"zip" und:"requires=city"`
+	}
+	invalidGroupConflict struct {
+		A option.Option[string] `und:"group=g:exactly-one"`
+		B option.Option[string] `und:"group=g:at-least-one"`
+	}
+	invalidGroupRequires struct {
+		A option.Option[string] `und:"requires=C"`
+	}
+	invalidGroupMalformed struct {
+		A option.Option[string] `und:"group=g:one"`
+	}
+)
+
+func TestValidate_group(t *testing.T) {
+	assert.NilError(t, validate.UndCheck(payment{}))
+	assert.NilError(t, validate.UndValidate(payment{
+		Card:  option.Some("1234"),
+		Bank:  und.Null[string](),
+		Email: option.Some("foo@example.com"),
+	}))
+
+	wallet := "wallet"
+	assert.NilError(t, validate.UndValidate(payment{
+		Wallet: &wallet,
+		Street: option.Some("street"),
+		City:   option.Some("city"),
+		Zip:    option.Some("zip"),
+	}))
+
+	err := validate.UndValidate(payment{}, validate.CollectAll())
+	t.Logf("err = %v", err)
+	assert.DeepEqual(
+		t,
+		[]string{"/card:group", "/bank:group", "/wallet:group", "/wallet:group", "/email:group"},
+		pointersAndCodes(err),
+	)
+	assert.ErrorContains(t, err, "validation failed at .card: input is a member of exactly one of group payment (card, bank, wallet) must be defined")
+
+	err = validate.UndValidate(
+		payment{
+			Card:   option.Some("1234"),
+			Bank:   und.Defined("bank"),
+			Email:  option.Some("foo@example.com"),
+			Street: option.Some("street"),
+			Zip:    option.Some("zip"),
+		},
+		validate.CollectAll(),
+	)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(
+		t,
+		[]string{
+			"/card:group", "/bank:group", "/wallet:group",
+			"/street:group", "/city:group",
+			"/city:requires", "/city:requires",
+		},
+		pointersAndCodes(err),
+	)
+	assert.ErrorContains(t, err, "validation failed at .city: input must be defined since street is defined")
+
+	assert.ErrorIs(t, validate.UndCheck(invalidGroupConflict{}), undtag.ErrMalformedGroup)
+	assert.ErrorIs(t, validate.UndCheck(invalidGroupRequires{}), undtag.ErrMalformedGroup)
+	assert.ErrorIs(t, validate.UndCheck(invalidGroupMalformed{}), undtag.ErrMalformedGroup)
+}
+
+type scoped struct {
+	ID    option.Option[string] `json:"id" und:"create:und;update:def"`
+	Name  und.Und[string]       `json:"name" und:"create:def;update:def,null,und"`
+	Email und.Und[string]       `json:"email" und:"def,und;update:def,null,und"`
+	Tags  []string              `json:"tags" und:"create:len>=1"`
+}
+
+func TestValidate_scope(t *testing.T) {
+	for _, tc := range []struct {
+		tag, scope, expected string
+	}{
+		{"def,und", "", "def,und"},
+		{"def,und", "create", "def,und"},
+		{"values:nonnull", "create", "values:nonnull"},
+		{"group=payment:exactly-one", "create", "group=payment:exactly-one"},
+		{"create:required;update:def,und", "", ""},
+		{"create:required;update:def,und", "create", "required"},
+		{"create:required;update:def,und", "update", "def,und"},
+		{"create:required;update:def,und", "delete", ""},
+		{"def,und;update:values:nonnull", "update", "values:nonnull"},
+		{"def,und;update:values:nonnull", "create", "def,und"},
+	} {
+		assert.Equal(t, tc.expected, undtag.ScopedOption(tc.tag, tc.scope), "tag = %q, scope = %q", tc.tag, tc.scope)
+	}
+
+	assert.NilError(t, validate.UndValidate(scoped{}))
+	assert.NilError(t, validate.UndValidateScope(scoped{
+		Name: und.Defined("name"),
+		Tags: []string{"foo"},
+	}, "create"))
+	assert.NilError(t, validate.UndValidateScope(scoped{
+		ID:    option.Some("id"),
+		Name:  und.Null[string](),
+		Email: und.Null[string](),
+	}, "update"))
+
+	err := validate.UndValidateScope(scoped{
+		ID:    option.Some("id"),
+		Email: und.Null[string](),
+	}, "create", validate.CollectAll())
+	t.Logf("err = %v", err)
+	assert.DeepEqual(t, []string{"/id", "/name", "/email", "/tags"}, pointers(err))
+
+	err = validate.UndValidateScope(scoped{Name: und.Null[string]()}, "update")
+	assert.ErrorContains(t, err, "validation failed at .id:")
+
+	// validators are cached per scope.
+	assert.NilError(t, validate.UndValidate(scoped{Email: und.Defined("foo@example.com")}))
+	assert.NilError(t, validate.UndCheckScope(scoped{}, "update"))
+	assert.ErrorIs(t, validate.UndCheckScope(invalidScoped{}, "update"), undtag.ErrUnknownOption)
+	// every segment is checked regardless of the scope.
+	assert.ErrorIs(t, validate.UndCheckScope(invalidScoped{}, "create"), undtag.ErrUnknownOption)
+	assert.ErrorIs(t, validate.UndCheck(invalidScoped{}), undtag.ErrUnknownOption)
+}
+
+type invalidScoped struct {
+	A option.Option[string] `und:"create:def;update:foo"`
+}
+
+type (
+	duplicateScope struct {
+		A option.Option[string] `und:"create:def;create:und"`
+	}
+	emptyScopeName struct {
+		A option.Option[string] `und:"create:def;:und"`
+	}
+	emptySegment struct {
+		A option.Option[string] `und:"create:def;;update:und"`
+	}
+	multipleUnscoped struct {
+		A option.Option[string] `und:"def;create:def;und"`
+	}
+	misspelledScope struct {
+		A option.Option[string] `und:"create:def;udpate:und"`
+		B option.Option[string] `und:"def;delete:und"`
+	}
+)
+
+func TestValidate_scope_check(t *testing.T) {
+	for _, v := range []any{duplicateScope{}, emptyScopeName{}, emptySegment{}, multipleUnscoped{}} {
+		for _, scope := range []string{"", "create", "update"} {
+			err := validate.UndCheckScope(v, scope)
+			assert.ErrorIs(t, err, undtag.ErrMalformedScope, "%T, scope = %q", v, scope)
+			vErrs := validate.Errors(err)
+			assert.Equal(t, 1, len(vErrs))
+			assert.Equal(t, validate.CodeInvalidTag, vErrs[0].Code())
+		}
+	}
+
+	assert.NilError(t, validate.UndCheck(misspelledScope{}))
+	assert.NilError(t, validate.UndCheckScope(misspelledScope{}, "update"))
+
+	err := validate.UndCheckScope(misspelledScope{}, "update", validate.AllowedScopes("create", "update"))
+	assert.ErrorIs(t, err, undtag.ErrUnknownScope)
+	assert.ErrorContains(t, err, `["udpate"]`)
+	assert.Equal(t, validate.CodeInvalidTag, validate.Errors(err)[0].Code())
+	assert.ErrorIs(
+		t,
+		validate.UndValidate(misspelledScope{}, validate.AllowedScopes("create", "update")),
+		undtag.ErrUnknownScope,
+	)
+	assert.NilError(t, validate.UndCheck(misspelledScope{}, validate.AllowedScopes("create", "udpate", "delete")))
+	assert.NilError(t, validate.UndCheck(misspelledScope{}, validate.AllowedScopes("create", "udpate"), validate.AllowedScopes("delete")))
+}
+
+type (
+	hooked struct {
+		Status und.Und[string]       `json:"status" und:"def,null,und"`
+		Reason option.Option[string] `json:"reason"`
+	}
+	hookedParent struct {
+		Child    hooked                        `json:"child"`
+		Children []hooked                      `json:"children"`
+		Opt      option.Option[hooked]         `json:"opt"`
+		Ela      elastic.Elastic[*hookedPtr]   `json:"ela"`
+		Map      map[string]und.Und[hookedPtr] `json:"map"`
+	}
+	hookedPtr struct {
+		A int `json:"a"`
+	}
+	hookedJoined struct {
+		A int
+	}
+)
+
+func (h hooked) UndValidateStruct() error {
+	if h.Status.IsNull() && h.Reason.IsNone() {
+		return validate.AppendValidationErrorDot(fmt.Errorf("reason must be defined if status is null"), "reason")
+	}
+	return nil
+}
+
+func (h *hookedPtr) UndValidateStruct() error {
+	if h.A < 0 {
+		return fmt.Errorf("a must not be negative")
+	}
+	return nil
+}
+
+func (h hookedJoined) UndValidateStruct() error {
+	return errors.Join(fmt.Errorf("foo"), fmt.Errorf("bar"))
+}
+
+func TestValidate_struct_validator(t *testing.T) {
+	nullStatus := hooked{Status: und.Null[string]()}
+	assert.NilError(t, validate.UndValidate(hookedParent{}))
+	assert.NilError(t, validate.UndValidate(hooked{Status: und.Null[string](), Reason: option.Some("foo")}))
+
+	err := validate.UndValidate(nullStatus)
+	assert.ErrorContains(t, err, "validation failed at .reason: reason must be defined if status is null")
+	assert.Equal(t, validate.CodeStruct, validate.Errors(err)[0].Code())
+
+	// pointer receiver on a non addressable value.
+	assert.ErrorContains(t, validate.UndValidate(hookedPtr{A: -1}), "a must not be negative")
+
+	err = validate.UndValidate(
+		hookedParent{
+			Child:    nullStatus,
+			Children: []hooked{{}, nullStatus},
+			Opt:      option.Some(nullStatus),
+			Ela:      elastic.FromValues(&hookedPtr{}, &hookedPtr{A: -1}),
+			Map:      map[string]und.Und[hookedPtr]{"foo": und.Defined(hookedPtr{A: -1})},
+		},
+		validate.CollectAll(),
+	)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(
+		t,
+		[]string{"/child/reason", "/children/1/reason", "/opt/reason", "/ela/1", "/map/foo"},
+		pointers(err),
+	)
+
+	err = validate.UndValidate(hookedJoined{}, validate.CollectAll())
+	assert.Equal(t, 2, len(validate.Errors(err)))
+}
+
+type (
+	rangedValues struct {
+		Range   elastic.Elastic[string]  `json:"range" und:"def,und,len=1..3"`
+		Pair    elastic.Elastic[string]  `json:"pair" und:"def,und,len>0,len<3"`
+		Unique  elastic.Elastic[string]  `json:"unique" und:"values:nonnull,values:unique"`
+		Nonzero elastic.Elastic[int]     `json:"nonzero" und:"values:nonzero"`
+		Sorted  elastic.Elastic[float64] `json:"sorted" und:"values:sorted,values:unique"`
+		Slice   []int                    `json:"slice" und:"def,und,len>=1,len<=2,values:sorted"`
+		Opt     option.Option[[]string]  `json:"opt" und:"def,und,values:unique"`
+	}
+	invalidLenRange struct {
+		A elastic.Elastic[string] `und:"len=3..1"`
+	}
+	invalidLenDouble struct {
+		A elastic.Elastic[string] `und:"len>1,len>=2"`
+	}
+	invalidLenEmpty struct {
+		A elastic.Elastic[string] `und:"len>=1,len<0"`
+	}
+	invalidValuesDouble struct {
+		A elastic.Elastic[string] `und:"values:unique,values:unique"`
+	}
+	invalidUnique struct {
+		A elastic.Elastic[[]int] `und:"values:unique"`
+	}
+	invalidSorted struct {
+		A elastic.Elastic[bool] `und:"values:sorted"`
+	}
+	invalidSortedMap struct {
+		A map[string]int `und:"values:sorted"`
+	}
+)
+
+func TestValidate_len_range_and_values(t *testing.T) {
+	assert.NilError(t, validate.UndValidate(rangedValues{}))
+	assert.NilError(t, validate.UndValidate(rangedValues{
+		Range:   elastic.FromValues("a", "b", "c"),
+		Pair:    elastic.FromValues("a", "b"),
+		Unique:  elastic.FromValues("a", "b"),
+		Nonzero: elastic.FromOptions(option.Some(1), option.None[int]()),
+		Sorted:  elastic.FromOptions(option.Some(-1.5), option.None[float64](), option.Some(2.0)),
+		Slice:   []int{1, 1},
+		Opt:     option.Some([]string{"a", "b"}),
+	}))
+
+	err := validate.UndValidate(
+		rangedValues{
+			Range:   elastic.FromValues("a", "b", "c", "d"),
+			Pair:    elastic.FromValues[string](),
+			Unique:  elastic.FromValues("a", "b", "a"),
+			Nonzero: elastic.FromValues(1, 0),
+			Sorted:  elastic.FromValues(2.0, 1.0),
+			Slice:   []int{2, 1},
+			Opt:     option.Some([]string{"a", "a"}),
+		},
+		validate.CollectAll(),
+	)
+	t.Logf("err = %v", err)
+	assert.DeepEqual(
+		t,
+		[]string{
+			"/range:len", "/pair:len", "/unique:values", "/nonzero:values",
+			"/sorted:values", "/slice:values", "/opt:values",
+		},
+		pointersAndCodes(err),
+	)
+	assert.ErrorContains(t, err, "validation failed at .range: input must be defined or undefined, and defined or must have length of between 1 and 3")
+	assert.ErrorContains(t, err, "validation failed at .unique: input must not contain null, and must not contain duplicate values")
+
+	vErr := validate.Errors(err)[0]
+	bin, jsonErr := json.Marshal(vErr)
+	assert.NilError(t, jsonErr)
+	assert.Assert(t, strings.Contains(string(bin), `"len":{"op":"..","len":1,"max":3}`), string(bin))
+
+	assert.ErrorIs(t, validate.UndCheck(invalidLenRange{}), undtag.ErrMalformedLen)
+	assert.ErrorIs(t, validate.UndCheck(invalidLenDouble{}), undtag.ErrMultipleOption)
+	assert.ErrorIs(t, validate.UndCheck(invalidLenEmpty{}), undtag.ErrMalformedLen)
+	assert.ErrorIs(t, validate.UndCheck(invalidValuesDouble{}), undtag.ErrMultipleOption)
+	assert.ErrorIs(t, validate.UndCheck(invalidUnique{}), undtag.ErrMalformedValues)
+	assert.ErrorIs(t, validate.UndCheck(invalidSorted{}), undtag.ErrMalformedValues)
+	assert.ErrorIs(t, validate.UndCheck(invalidSortedMap{}), undtag.ErrMalformedValues)
+}
+
+func TestValidate_tag_parse_error(t *testing.T) {
+	type typo struct {
+		A option.Option[string] `json:"a" und:"def,requried"`
+	}
+	err := validate.UndCheck(typo{})
+	assert.ErrorIs(t, err, undtag.ErrUnknownOption)
+	var pErr *undtag.ParseError
+	assert.Assert(t, errors.As(err, &pErr))
+	assert.Equal(t, "a", pErr.Field)
+	assert.Equal(t, "required", pErr.Suggestion)
+	assert.Equal(t, validate.CodeInvalidTag, validate.Errors(err)[0].Code())
+	// the field is printed only once.
+	assert.Equal(
+		t,
+		`validation failed at .a: und tag "def,requried": option "requried" at offset 4: unknown option (did you mean "required"?)`,
+		err.Error(),
+	)
+
+	type scopedTypo struct {
+		A option.Option[string] `json:"a" und:"create:required;update:def,requried"`
+	}
+	for _, scope := range []string{"", "create", "update"} {
+		err := validate.UndCheckScope(scopedTypo{}, scope)
+		var pErr *undtag.ParseError
+		assert.Assert(t, errors.As(err, &pErr))
+		assert.Equal(t, "create:required;update:def,requried", pErr.Tag)
+		assert.Equal(t, "requried", pErr.Token)
+		assert.Equal(t, 27, pErr.Offset)
+		assert.Equal(t, "a", pErr.Field)
+	}
+}
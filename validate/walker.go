@@ -0,0 +1,136 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+)
+
+// errStop is returned from validators to stop walking when enough errors are collected.
+var errStop = errors.New("stop")
+
+// walker holds states of a single UndValidate call.
+type walker struct {
+	opts validateOptions
+	// path is the current path from the root. Unlike ValidationError.fieldChain, it is in root-first order.
+	path []fieldSelector
+	errs []error
+	// visits holds pointers to structs already visited.
+	// The value is true while values pointed by it are being validated.
+	visits map[visitKey]bool
+}
+
+type visitKey struct {
+	rt  reflect.Type
+	ptr uintptr
+}
+
+// enter marks rv, a non-nil pointer, as visited.
+// skip is true if rv is already visited, and then err is ErrCycle if rv is in the current path
+// and the policy is CycleError.
+// Unless skip is true, leave must be called after validating values pointed by rv.
+func (w *walker) enter(rv reflect.Value) (skip bool, err error) {
+	key := visitKey{rv.Type(), rv.Pointer()}
+	onPath, ok := w.visits[key]
+	if ok {
+		if onPath && w.opts.cyclePolicy == CycleError {
+			return true, w.report(&ValidationError{err: ErrCycle, code: CodeCycle})
+		}
+		return true, nil
+	}
+	if w.visits == nil {
+		w.visits = make(map[visitKey]bool)
+	}
+	w.visits[key] = true
+	return false, nil
+}
+
+func (w *walker) leave(rv reflect.Value) {
+	w.visits[visitKey{rv.Type(), rv.Pointer()}] = false
+}
+
+func (w *walker) tooDeep() bool {
+	return w.opts.maxDepth > 0 && len(w.path) > w.opts.maxDepth
+}
+
+func newWalker(opts []ValidateOption) *walker {
+	w := &walker{opts: validateOptions{nameTag: DefaultFieldNameTag, maxDepth: DefaultMaxDepth}}
+	for _, opt := range opts {
+		opt(&w.opts)
+	}
+	return w
+}
+
+func (w *walker) push(sel fieldSelector) {
+	w.path = append(w.path, sel)
+}
+
+func (w *walker) pop() {
+	w.path = w.path[:len(w.path)-1]
+}
+
+// report records err as a violation at the current path.
+// A non-nil returned error means walking must stop and the error should be propagated as is.
+func (w *walker) report(err error) error {
+	vErr := w.wrap(err)
+	if !w.opts.collectAll {
+		return vErr
+	}
+	w.errs = append(w.errs, vErr)
+	if w.opts.maxErrors > 0 && len(w.errs) >= w.opts.maxErrors {
+		return errStop
+	}
+	return nil
+}
+
+// wrap converts err into *ValidationError prefixed with the current path.
+func (w *walker) wrap(err error) *ValidationError {
+	vErr, ok := err.(*ValidationError)
+	if ok {
+		cloned := *vErr
+		cloned.fieldChain = slices.Clone(vErr.fieldChain)
+		vErr = &cloned
+	} else {
+		vErr = &ValidationError{err: err}
+	}
+	for _, sel := range slices.Backward(w.path) {
+		vErr.fieldChain = append(vErr.fieldChain, sel)
+	}
+	return vErr
+}
+
+// result converts the error returned from the root validator into the result of UndValidate.
+func (w *walker) result(err error) error {
+	if !w.opts.collectAll {
+		return err
+	}
+	if len(w.errs) == 0 {
+		return nil
+	}
+	return errors.Join(w.errs...)
+}
+
+// Errors returns all *ValidationError in err,
+// flattening errors joined by errors.Join, e.g. ones returned by UndValidate with [CollectAll].
+func Errors(err error) []*ValidationError {
+	var errs []*ValidationError
+	var walk func(err error)
+	walk = func(err error) {
+		switch x := err.(type) {
+		case nil:
+		case *ValidationError:
+			errs = append(errs, x)
+		case interface{ Unwrap() []error }:
+			for _, err := range x.Unwrap() {
+				walk(err)
+			}
+		default:
+			var vErr *ValidationError
+			if errors.As(err, &vErr) {
+				errs = append(errs, vErr)
+			}
+		}
+	}
+	walk(err)
+	return errs
+}